@@ -0,0 +1,172 @@
+package iSlogger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy decides what happens when the async queue is full
+type OverflowPolicy string
+
+const (
+	Block      OverflowPolicy = "block"       // Wait for room in the queue (default)
+	DropNewest OverflowPolicy = "drop_newest" // Discard the record being logged
+	DropOldest OverflowPolicy = "drop_oldest" // Discard the oldest queued record to make room
+)
+
+// overflowReportInterval is the minimum time between queue overflow warnings
+const overflowReportInterval = time.Second
+
+// asyncRecord is a record waiting for the background writer. Barrier items
+// carry no record and are closed once every record queued before them is handled.
+type asyncRecord struct {
+	logger  *Logger
+	ctx     context.Context
+	record  slog.Record
+	barrier chan struct{}
+}
+
+// asyncQueue hands records to a background writer through a bounded channel
+type asyncQueue struct {
+	records chan asyncRecord
+	policy  OverflowPolicy
+	root    *Logger // Writes the overflow warnings
+	stats   *dropStats
+	stopped chan struct{} // Closed when the writer has drained the queue
+
+	// Owned by the writer goroutine
+	reported   uint64 // Dropped count covered by the last overflow warning
+	lastReport time.Time
+
+	mu     sync.RWMutex // Held for reading while enqueueing, so close can't race a send
+	closed bool
+}
+
+// newAsyncQueue creates a queue for the root logger and starts its background writer
+func newAsyncQueue(size int, policy OverflowPolicy, root *Logger) *asyncQueue {
+	q := &asyncQueue{
+		records: make(chan asyncRecord, size),
+		policy:  policy,
+		root:    root,
+		stats:   &root.stats,
+		stopped: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// run writes queued records until the queue is closed and empty
+func (q *asyncQueue) run() {
+	defer close(q.stopped)
+
+	ticker := time.NewTicker(overflowReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case item, ok := <-q.records:
+			if !ok {
+				q.reportOverflow(true)
+				return
+			}
+			if item.barrier != nil {
+				q.reportOverflow(false)
+				close(item.barrier)
+				continue
+			}
+			item.logger.write(item.ctx, item.record)
+			q.reportOverflow(false)
+		case <-ticker.C:
+			q.reportOverflow(false)
+		}
+	}
+}
+
+// reportOverflow writes a WARN record with the number of records dropped since
+// the last report, at most once per overflowReportInterval unless forced.
+// It is written directly, so it can't be dropped by the queue itself.
+func (q *asyncQueue) reportOverflow(force bool) {
+	dropped := q.stats.asyncDropped.Load()
+	if dropped == q.reported || (!force && time.Since(q.lastReport) < overflowReportInterval) {
+		return
+	}
+
+	record := slog.NewRecord(q.root.now(), slog.LevelWarn, "log queue overflow", 0)
+	record.AddAttrs(slog.Uint64("dropped", dropped-q.reported))
+	q.root.write(context.Background(), record)
+
+	q.reported = dropped
+	q.lastReport = time.Now()
+}
+
+// enqueue queues the record, applying the overflow policy when the queue is full.
+// It reports false if the queue is closed and the caller must write the record itself.
+func (q *asyncQueue) enqueue(item asyncRecord) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return false
+	}
+
+	switch q.policy {
+	case DropNewest:
+		select {
+		case q.records <- item:
+		default:
+			q.stats.asyncDropped.Add(1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case q.records <- item:
+				return true
+			default:
+			}
+			// Make room by discarding the oldest record, if the writer hasn't taken it already.
+			// Barriers are never dropped: one goes back in the queue, so it is still only
+			// closed by the writer once the records queued before it are written.
+			select {
+			case oldest := <-q.records:
+				if oldest.barrier != nil {
+					q.records <- oldest
+				} else {
+					q.stats.asyncDropped.Add(1)
+				}
+			default:
+			}
+		}
+	default:
+		q.records <- item
+	}
+	return true
+}
+
+// drain waits until every record queued so far has been written
+func (q *asyncQueue) drain() {
+	barrier := make(chan struct{})
+
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		<-q.stopped
+		return
+	}
+	q.records <- asyncRecord{barrier: barrier} // Barriers are never dropped on arrival
+	q.mu.RUnlock()
+
+	<-barrier
+}
+
+// close stops accepting records and waits for the writer to drain the queue
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.records)
+	}
+	q.mu.Unlock()
+
+	<-q.stopped
+}
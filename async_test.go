@@ -0,0 +1,292 @@
+package iSlogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stalledAsyncLogger returns an async logger whose writer blocks on the first
+// record until release is closed, so the queue can be filled deterministically
+func stalledAsyncLogger(t *testing.T, queueSize int, policy OverflowPolicy) (logger *Logger, release chan struct{}) {
+	t.Helper()
+
+	started := make(chan struct{})
+	release = make(chan struct{})
+	stall := func(level slog.Level, msg string, attrs []slog.Attr) bool {
+		if msg == "stall" {
+			close(started)
+			<-release
+		}
+		return true
+	}
+
+	config := DefaultConfig().
+		WithAppName("test-async").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithInMemoryTail(100).
+		WithCondition(stall).
+		WithAsync(queueSize).
+		WithAsyncOverflow(policy)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Info("stall")
+	<-started
+	return logger, release
+}
+
+// tailMessages returns the messages of the records kept in memory
+func tailMessages(logger *Logger) []string {
+	var messages []string
+	for _, line := range logger.Tail() {
+		_, msg, _ := strings.Cut(line, "msg=")
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestAsync_DropNewest(t *testing.T) {
+	logger, release := stalledAsyncLogger(t, 2, DropNewest)
+	defer logger.Close()
+
+	for i := 1; i <= 4; i++ {
+		logger.Info(fmt.Sprintf("r%d", i))
+	}
+	close(release)
+	logger.Flush()
+
+	if got := strings.Join(tailMessages(logger), ","); got != `stall,"log queue overflow" dropped=2,r1,r2` {
+		t.Errorf("Expected the newest records to be dropped, got %s", got)
+	}
+	if got := logger.Stats().AsyncDropped; got != 2 {
+		t.Errorf("Expected 2 dropped records, got %d", got)
+	}
+}
+
+func TestAsync_DropOldest(t *testing.T) {
+	logger, release := stalledAsyncLogger(t, 2, DropOldest)
+	defer logger.Close()
+
+	for i := 1; i <= 4; i++ {
+		logger.Info(fmt.Sprintf("r%d", i))
+	}
+	close(release)
+	logger.Flush()
+
+	if got := strings.Join(tailMessages(logger), ","); got != `stall,"log queue overflow" dropped=2,r3,r4` {
+		t.Errorf("Expected the oldest records to be dropped, got %s", got)
+	}
+	if got := logger.Stats().AsyncDropped; got != 2 {
+		t.Errorf("Expected 2 dropped records, got %d", got)
+	}
+}
+
+func TestAsync_DropOldestKeepsBarriers(t *testing.T) {
+	logger, release := stalledAsyncLogger(t, 2, DropOldest)
+	defer logger.Close()
+
+	logger.Info("r1")
+	flushed := make(chan struct{})
+	go func() {
+		logger.Flush()
+		close(flushed)
+	}()
+	for len(logger.async.records) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Evicts r1, then reaches the barrier
+	logger.Info("r2")
+	logger.Info("r3")
+
+	select {
+	case <-flushed:
+		t.Error("Expected Flush to wait for the stalled record")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-flushed
+}
+
+func TestAsync_OverflowWarning(t *testing.T) {
+	logger, release := stalledAsyncLogger(t, 1, DropNewest)
+
+	for i := range 100 {
+		logger.Info("flood", "n", i)
+	}
+	close(release)
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	if got := logger.Stats().AsyncDropped; got != 99 {
+		t.Errorf("Expected 99 dropped records, got %d", got)
+	}
+
+	infoPath, errorPath := logger.GetCurrentLogPaths()
+	warnings, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if got := strings.Count(string(warnings), `level=WARN msg="log queue overflow" dropped=99`); got != 1 {
+		t.Errorf("Expected one overflow warning with the dropped count, got:\n%s", warnings)
+	}
+
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	if got := strings.Count(string(content), "msg=flood"); got != 1 {
+		t.Errorf("Expected 1 flood record to be written, got %d", got)
+	}
+}
+
+func TestAsync_Block(t *testing.T) {
+	logger, release := stalledAsyncLogger(t, 1, Block)
+	defer logger.Close()
+
+	logger.Info("r1") // Fills the queue
+
+	returned := make(chan struct{})
+	go func() {
+		logger.Info("r2")
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+		t.Fatal("Expected logging to block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-returned
+	logger.Flush()
+
+	if got := strings.Join(tailMessages(logger), ","); got != "stall,r1,r2" {
+		t.Errorf("Expected no records to be dropped, got %s", got)
+	}
+	if got := logger.Stats().AsyncDropped; got != 0 {
+		t.Errorf("Expected no dropped records, got %d", got)
+	}
+}
+
+func TestAsync_DrainOnClose(t *testing.T) {
+	logger, release := stalledAsyncLogger(t, 100, Block)
+
+	for i := range 50 {
+		logger.Info("queued", "n", i)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	if got := strings.Count(string(content), "msg=queued"); got != 50 {
+		t.Errorf("Expected all 50 queued records to be written on close, got %d", got)
+	}
+}
+
+func TestAsync_StackTrace(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-async-stack").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithStackTrace(slog.LevelError).
+		WithAsync(10)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Error("Queued failure")
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if !strings.Contains(string(content), `stacktrace="github.com/sarff/iSlogger.TestAsync_StackTrace`) {
+		t.Errorf("Expected the stack trace to start at the caller, got: %s", content)
+	}
+	if strings.Contains(string(content), "asyncQueue") {
+		t.Errorf("Expected no writer goroutine frames, got: %s", content)
+	}
+}
+
+func TestShutdown_DrainsPendingRecords(t *testing.T) {
+	logger, release := stalledAsyncLogger(t, 100, Block)
+
+	for i := range 50 {
+		logger.Info("queued", "n", i)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := logger.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	logger.Info("after shutdown")
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	if got := strings.Count(string(content), "msg=queued"); got != 50 {
+		t.Errorf("Expected all 50 queued records to be written, got %d", got)
+	}
+	if strings.Contains(string(content), "after shutdown") {
+		t.Errorf("Expected records logged after Shutdown to be discarded, got: %s", content)
+	}
+}
+
+func TestShutdown_ExpiredContext(t *testing.T) {
+	logger, release := stalledAsyncLogger(t, 100, Block)
+	defer close(release)
+
+	logger.Info("queued")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	returned := make(chan error, 1)
+	go func() {
+		returned <- logger.Shutdown(ctx)
+	}()
+
+	select {
+	case err := <-returned:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Shutdown to return promptly with a cancelled context")
+	}
+}
@@ -0,0 +1,11 @@
+package iSlogger
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Duration returns an attribute holding d as integer milliseconds
+func Duration(key string, d time.Duration) slog.Attr {
+	return slog.Int64(key, d.Milliseconds())
+}
@@ -0,0 +1,173 @@
+package iSlogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	attr := Duration("duration_ms", 1500*time.Millisecond)
+	if attr.Key != "duration_ms" || attr.Value.Int64() != 1500 {
+		t.Errorf("Expected duration_ms=1500, got %s=%s", attr.Key, attr.Value)
+	}
+}
+
+func TestDurationAsMillis(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-duration").
+		WithLogDir("test-logs-duration").
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithDurationAsMillis(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-duration")
+
+	logger.Info("Request", "duration", 150*time.Millisecond, "timeout", 2*time.Second)
+
+	lines := logger.Tail()
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], "duration=150 timeout=2000") {
+		t.Errorf("Expected durations as integer milliseconds, got: %s", lines[0])
+	}
+
+	if err := logger.Reconfigure(config.WithJSONFormat(true)); err != nil {
+		t.Fatalf("Failed to reconfigure: %v", err)
+	}
+	logger.Info("Request", "duration", 150*time.Millisecond)
+
+	lines = logger.Tail()
+	if !strings.Contains(lines[len(lines)-1], `"duration":150`) {
+		t.Errorf("Expected JSON duration as integer milliseconds, got: %s", lines[len(lines)-1])
+	}
+}
+
+func TestOriginFields(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-origin").
+		WithLogDir("test-logs-origin").
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithHostField(true).
+		WithPIDField(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-origin")
+
+	logger.Info("Direct", "key", "value")
+	logger.With("request_id", "r1").Info("Derived")
+
+	host, _ := os.Hostname()
+	origin := fmt.Sprintf("host=%s pid=%d", host, os.Getpid())
+
+	lines := logger.Tail()
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], origin+" key=value") {
+		t.Errorf("Expected origin fields before user attributes, got: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], origin+" request_id=r1") {
+		t.Errorf("Expected origin fields to survive With, got: %s", lines[1])
+	}
+}
+
+func TestFlattenGroups(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-flatten").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithJSONFormat(true).
+		WithFieldMask("password", "***").
+		WithFlattenGroups(".")
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Slog().WithGroup("http").With("method", "POST").
+		Info("Signup", slog.Group("user", "id", 7, slog.Group("auth", "password", "secret")))
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(content, &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q: %v", content, err)
+	}
+	expected := map[string]any{
+		"http.method":             "POST",
+		"http.user.id":            float64(7),
+		"http.user.auth.password": "***",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Expected %s = %v, got %v in %s", key, value, record[key], content)
+		}
+	}
+	for _, key := range []string{"http", "user"} {
+		if _, nested := record[key]; nested {
+			t.Errorf("Expected no nested %q object, got: %s", key, content)
+		}
+	}
+}
+
+func TestGlobalFields(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-global-fields").
+		WithLogDir("test-logs-global-fields").
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithGlobalFields("service", "billing", "version", "1.2.3")
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-global-fields")
+
+	logger.Info("Direct")
+	logger.With("request_id", "r1").With("user", "john").Info("Derived")
+	if err := logger.RotateNow(); err != nil {
+		t.Fatalf("Failed to rotate: %v", err)
+	}
+	logger.Info("After rotation")
+
+	lines := logger.Tail()
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "service=billing version=1.2.3") {
+			t.Errorf("Expected global fields, got: %s", line)
+		}
+		if strings.Count(line, "service=") != 1 {
+			t.Errorf("Expected global fields exactly once, got: %s", line)
+		}
+	}
+	if !strings.Contains(lines[1], "version=1.2.3 request_id=r1 user=john") {
+		t.Errorf("Expected With attributes after global fields, got: %s", lines[1])
+	}
+}
@@ -4,11 +4,21 @@ import (
 	"bytes"
 	"io"
 	"log/slog"
-	"strings"
 	"sync"
 	"time"
 )
 
+// FlushReason tells why a buffer was flushed
+type FlushReason string
+
+const (
+	FlushBySize     FlushReason = "size"     // The buffer filled up
+	FlushByLevel    FlushReason = "level"    // A record at FlushOnLevel or above was written
+	FlushByInterval FlushReason = "interval" // The periodic flush
+	FlushManual     FlushReason = "manual"   // Flush or Sync
+	FlushOnClose    FlushReason = "close"    // The file closed, on Close or a rotation
+)
+
 // bufferedWriter provides buffered writing with automatic flushing
 type bufferedWriter struct {
 	writer        io.Writer
@@ -17,6 +27,10 @@ type bufferedWriter struct {
 	size          int
 	flushInterval time.Duration
 	flushOnLevel  slog.Level
+	minFlushBytes int           // Periodic flushes skip smaller buffers...
+	maxFlushAge   time.Duration // ...unless their oldest data is this old
+	bufferedSince time.Time     // When the oldest unflushed data was written
+	onFlush       func(bytes int, reason FlushReason)
 	stopChan      chan struct{}
 	once          sync.Once
 }
@@ -59,18 +73,18 @@ func (bw *bufferedWriter) Write(p []byte) (n int, err error) {
 		return bw.writer.Write(p)
 	}
 
-	// Check if this is a high-priority log that should be flushed immediately
-	shouldFlushImmediately := bw.shouldFlushImmediately(p)
-
 	// Write to buffer
+	if bw.buffer.Len() == 0 {
+		bw.bufferedSince = time.Now()
+	}
 	n, err = bw.buffer.Write(p)
 	if err != nil {
 		return n, err
 	}
 
-	// Flush if buffer is full, or if this is a high-priority log
-	if bw.buffer.Len() >= bw.size || shouldFlushImmediately {
-		if flushErr := bw.flushLocked(); flushErr != nil {
+	// Flush if buffer is full
+	if bw.buffer.Len() >= bw.size {
+		if flushErr := bw.flushLocked(FlushBySize); flushErr != nil {
 			return n, flushErr
 		}
 	}
@@ -78,56 +92,75 @@ func (bw *bufferedWriter) Write(p []byte) (n int, err error) {
 	return n, nil
 }
 
-// shouldFlushImmediately checks if the log entry should trigger immediate flush
-func (bw *bufferedWriter) shouldFlushImmediately(p []byte) bool {
-	logStr := string(p)
-
-	// Check for high-priority levels based on flushOnLevel
-	switch bw.flushOnLevel {
-	case slog.LevelDebug:
-		return true // Flush on any level
-	case slog.LevelInfo:
-		return strings.Contains(logStr, "level=INFO") ||
-			strings.Contains(logStr, "level=WARN") ||
-			strings.Contains(logStr, "level=ERROR") ||
-			strings.Contains(logStr, `"level":"INFO"`) ||
-			strings.Contains(logStr, `"level":"WARN"`) ||
-			strings.Contains(logStr, `"level":"ERROR"`)
-	case slog.LevelWarn:
-		return strings.Contains(logStr, "level=WARN") ||
-			strings.Contains(logStr, "level=ERROR") ||
-			strings.Contains(logStr, `"level":"WARN"`) ||
-			strings.Contains(logStr, `"level":"ERROR"`)
-	case slog.LevelError:
-		return strings.Contains(logStr, "level=ERROR") ||
-			strings.Contains(logStr, `"level":"ERROR"`)
-	}
+// setMinFlush makes periodic flushes skip buffers smaller than minBytes
+// until their oldest data is maxAge old
+func (bw *bufferedWriter) setMinFlush(minBytes int, maxAge time.Duration) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	bw.minFlushBytes = minBytes
+	bw.maxFlushAge = maxAge
+}
 
-	return false
+// setFlushHook makes every flush that writes data report its size and reason to fn.
+// fn runs with the writer locked, so it must not log to the same logger.
+func (bw *bufferedWriter) setFlushHook(fn func(bytes int, reason FlushReason)) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	bw.onFlush = fn
+}
+
+// flushForLevel flushes the buffer after a record at the given level was written,
+// if the level is at or above flushOnLevel (any level for flushOnLevel DEBUG)
+func (bw *bufferedWriter) flushForLevel(level slog.Level) error {
+	if bw.size == 0 || level < bw.flushOnLevel && bw.flushOnLevel != slog.LevelDebug {
+		return nil
+	}
+	return bw.flush(FlushByLevel)
 }
 
 // Flush flushes the buffer to the underlying writer
 func (bw *bufferedWriter) Flush() error {
+	return bw.flush(FlushManual)
+}
+
+// flush flushes the buffer for the given reason
+func (bw *bufferedWriter) flush(reason FlushReason) error {
 	bw.mu.Lock()
 	defer bw.mu.Unlock()
-	return bw.flushLocked()
+	return bw.flushLocked(reason)
 }
 
 // flushLocked flushes the buffer without acquiring the lock (must be called with lock held)
-func (bw *bufferedWriter) flushLocked() error {
+func (bw *bufferedWriter) flushLocked(reason FlushReason) error {
 	if bw.buffer.Len() == 0 {
 		return nil
 	}
 
+	size := bw.buffer.Len()
 	_, err := bw.writer.Write(bw.buffer.Bytes())
 	if err != nil {
 		return err
 	}
 
 	bw.buffer.Reset()
+	if bw.onFlush != nil {
+		bw.onFlush(size, reason)
+	}
 	return nil
 }
 
+// flushIfDue flushes the buffer if it holds at least minFlushBytes or its
+// oldest data has reached maxFlushAge
+func (bw *bufferedWriter) flushIfDue() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if bw.buffer.Len() < bw.minFlushBytes && time.Since(bw.bufferedSince) < bw.maxFlushAge {
+		return nil
+	}
+	return bw.flushLocked(FlushByInterval)
+}
+
 // autoFlush periodically flushes the buffer
 func (bw *bufferedWriter) autoFlush() {
 	ticker := time.NewTicker(bw.flushInterval)
@@ -136,7 +169,7 @@ func (bw *bufferedWriter) autoFlush() {
 	for {
 		select {
 		case <-ticker.C:
-			bw.Flush()
+			bw.flushIfDue()
 		case <-bw.stopChan:
 			return
 		}
@@ -152,7 +185,7 @@ func (bw *bufferedWriter) Close() error {
 	})
 
 	// Final flush
-	return bw.Flush()
+	return bw.flush(FlushOnClose)
 }
 
 // Sync is an alias for Flush to match io interfaces
@@ -3,7 +3,9 @@ package iSlogger
 import (
 	"bytes"
 	"log/slog"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -54,15 +56,15 @@ func TestBufferedWriter_FlushOnLevel(t *testing.T) {
 	defer bw.Close()
 
 	// Write INFO level - should not flush immediately
-	infoData := []byte(`{"level":"INFO","msg":"info message"}`)
-	bw.Write(infoData)
+	bw.Write([]byte(`{"level":"INFO","msg":"info message"}`))
+	bw.flushForLevel(slog.LevelInfo)
 	if buf.Len() > 0 {
 		t.Fatal("INFO message should not flush immediately")
 	}
 
 	// Write WARN level - should flush immediately
-	warnData := []byte(`{"level":"WARN","msg":"warning message"}`)
-	bw.Write(warnData)
+	bw.Write([]byte(`{"level":"WARN","msg":"warning message"}`))
+	bw.flushForLevel(slog.LevelWarn)
 	if buf.Len() == 0 {
 		t.Fatal("WARN message should trigger immediate flush")
 	}
@@ -96,6 +98,33 @@ func TestBufferedWriter_ManualFlush(t *testing.T) {
 	}
 }
 
+func TestBufferedWriter_FlushHook(t *testing.T) {
+	type flush struct {
+		bytes  int
+		reason FlushReason
+	}
+	var flushes []flush
+
+	bw := newBufferedWriter(&bytes.Buffer{}, 20, 0, slog.LevelError)
+	bw.setFlushHook(func(bytes int, reason FlushReason) {
+		flushes = append(flushes, flush{bytes, reason})
+	})
+
+	bw.Write([]byte("level=INFO msg=12345678\n")) // 24 bytes, over the size
+	bw.Write([]byte("level=ERROR msg=x\n"))
+	bw.flushForLevel(slog.LevelError) // Flushed for its level
+	bw.Write([]byte("short\n"))
+	bw.Flush()
+	bw.Flush() // Nothing buffered, no report
+	bw.Write([]byte("tail\n"))
+	bw.Close()
+
+	expected := []flush{{24, FlushBySize}, {18, FlushByLevel}, {6, FlushManual}, {5, FlushOnClose}}
+	if !slices.Equal(flushes, expected) {
+		t.Errorf("Expected flushes %v, got %v", expected, flushes)
+	}
+}
+
 func TestBufferedWriter_AutoFlush(t *testing.T) {
 	buf := &bytes.Buffer{}
 	bw := newBufferedWriter(buf, 1000, 50*time.Millisecond, slog.LevelError)
@@ -168,3 +197,52 @@ func TestBufferedWriter_Close(t *testing.T) {
 		t.Fatal("Flushed data should contain original message")
 	}
 }
+
+// lockedBuffer is a bytes.Buffer safe to read while the auto-flush goroutine writes
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestBufferedWriter_MinFlushBytes(t *testing.T) {
+	out := &lockedBuffer{}
+	bw := newBufferedWriter(out, 1000, 10*time.Millisecond, slog.LevelError)
+	bw.setMinFlush(100, 200*time.Millisecond)
+	defer bw.Close()
+
+	bw.Write([]byte("small\n"))
+
+	// Several ticks pass without reaching the threshold or the max age
+	time.Sleep(60 * time.Millisecond)
+	if got := out.String(); got != "" {
+		t.Fatalf("Expected sub-threshold data to stay buffered, got: %q", got)
+	}
+
+	// The max age eventually forces the flush
+	deadline := time.Now().Add(2 * time.Second)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := out.String(); got != "small\n" {
+		t.Fatalf("Expected the max age to flush buffered data, got: %q", got)
+	}
+
+	// Reaching the threshold flushes on the next tick
+	bw.Write(bytes.Repeat([]byte("x"), 150))
+	time.Sleep(60 * time.Millisecond)
+	if got := out.String(); len(got) != len("small\n")+150 {
+		t.Errorf("Expected data above the threshold to be flushed on a tick, got %d bytes", len(got))
+	}
+}
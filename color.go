@@ -0,0 +1,128 @@
+package iSlogger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// ANSI color codes used for console level tokens
+const (
+	colorReset  = "\x1b[0m"
+	colorGray   = "\x1b[90m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorRed    = "\x1b[31m"
+)
+
+// isTerminal reports whether the file is attached to a terminal
+var isTerminal = func(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorWriter colorizes the level token of text formatted records. The color
+// comes from the level of the record being written, set by colorHandler, so
+// custom and renamed levels are colored like the standard level below them.
+type colorWriter struct {
+	writer io.Writer
+	key    []byte     // Level key followed by '='
+	mu     sync.Mutex // Held by colorHandler while a record is written
+	level  slog.Level // Level of the record being written
+}
+
+// Write colorizes the level token and writes the record
+func (cw *colorWriter) Write(p []byte) (n int, err error) {
+	valueStart := 0
+	if !bytes.HasPrefix(p, cw.key) {
+		idx := bytes.Index(p, append([]byte{' '}, cw.key...))
+		if idx < 0 {
+			return cw.writer.Write(p)
+		}
+		valueStart = idx + 1
+	}
+	valueStart += len(cw.key)
+
+	valueEnd := len(p)
+	if end := bytes.IndexAny(p[valueStart:], " \n"); end >= 0 {
+		valueEnd = valueStart + end
+	}
+
+	color := levelColor(cw.level)
+	colored := make([]byte, 0, len(p)+len(color)+len(colorReset))
+	colored = append(colored, p[:valueStart]...)
+	colored = append(colored, color...)
+	colored = append(colored, p[valueStart:valueEnd]...)
+	colored = append(colored, colorReset...)
+	colored = append(colored, p[valueEnd:]...)
+
+	if _, err := cw.writer.Write(colored); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// colorHandler tells its colorWriter the level of each record it writes
+type colorHandler struct {
+	handler slog.Handler
+	writer  *colorWriter
+}
+
+// Enabled reports whether the wrapped handler is enabled for the level
+func (h *colorHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle writes the record with the writer set to its level
+func (h *colorHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.writer.mu.Lock()
+	defer h.writer.mu.Unlock()
+	h.writer.level = record.Level
+	return h.handler.Handle(ctx, record)
+}
+
+// WithAttrs creates a new handler with additional attributes
+func (h *colorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &colorHandler{handler: h.handler.WithAttrs(attrs), writer: h.writer}
+}
+
+// WithGroup creates a new handler with a group
+func (h *colorHandler) WithGroup(name string) slog.Handler {
+	return &colorHandler{handler: h.handler.WithGroup(name), writer: h.writer}
+}
+
+// consoleWriter returns the writer for a console stream, colorized or indented if enabled
+func (c Config) consoleWriter(f *os.File) io.Writer {
+	if c.PrettyJSON && c.consoleFormat() == FormatJSON {
+		return &prettyJSONWriter{writer: f}
+	}
+	if c.consoleFormat() != FormatText || !c.colorEnabled(f) {
+		return f
+	}
+	return &colorWriter{writer: f, key: []byte(c.builtinKey(slog.LevelKey) + "=")}
+}
+
+// colorEnabled reports whether console output to f should be colorized
+func (c Config) colorEnabled(f *os.File) bool {
+	return c.Color && os.Getenv("NO_COLOR") == "" && isTerminal(f)
+}
+
+// levelColor returns the color for a level, matching the nearest standard level below it
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return colorRed
+	case level >= slog.LevelWarn:
+		return colorYellow
+	case level >= slog.LevelInfo:
+		return colorGreen
+	default:
+		return colorGray
+	}
+}
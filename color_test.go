@@ -0,0 +1,164 @@
+package iSlogger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestColorWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cw := &colorWriter{writer: buf, key: []byte("level=")}
+
+	tests := []struct {
+		level    slog.Level
+		input    string
+		expected string
+	}{
+		{slog.LevelDebug, "level=DEBUG msg=a\n", "level=" + colorGray + "DEBUG" + colorReset + " msg=a\n"},
+		{slog.LevelInfo, "level=INFO msg=a\n", "level=" + colorGreen + "INFO" + colorReset + " msg=a\n"},
+		{slog.LevelWarn, "level=WARN msg=a\n", "level=" + colorYellow + "WARN" + colorReset + " msg=a\n"},
+		{slog.LevelError, "level=ERROR msg=a\n", "level=" + colorRed + "ERROR" + colorReset + " msg=a\n"},
+		{slog.Level(12), "t=1 level=CRITICAL msg=a\n", "t=1 level=" + colorRed + "CRITICAL" + colorReset + " msg=a\n"},
+		{slog.LevelInfo, "msg=no-level\n", "msg=no-level\n"},
+		{slog.LevelInfo, "loglevel=x\n", "loglevel=x\n"},
+	}
+
+	for _, test := range tests {
+		buf.Reset()
+		cw.level = test.level
+		n, err := cw.Write([]byte(test.input))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if n != len(test.input) {
+			t.Errorf("Expected %d bytes written, got %d", len(test.input), n)
+		}
+		if buf.String() != test.expected {
+			t.Errorf("colorWriter(%q) = %q, expected %q", test.input, buf.String(), test.expected)
+		}
+	}
+}
+
+func TestColorOutput_RenamedLevels(t *testing.T) {
+	oldIsTerminal := isTerminal
+	isTerminal = func(f *os.File) bool { return true }
+	defer func() { isTerminal = oldIsTerminal }()
+	t.Setenv("NO_COLOR", "")
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() {
+		os.Stdout = oldStdout
+	}()
+
+	config := DefaultConfig().
+		WithAppName("color-renamed").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(true).
+		WithColor(true).
+		WithoutBuffering().
+		WithLevelKey("severity").
+		WithLevelNames(map[slog.Level]string{12: "CRITICAL", slog.LevelInfo: "NOTICE"})
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Info("Renamed info")
+	logger.Log(context.Background(), 12, "Critical message")
+	logger.Close()
+
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	for _, expected := range []string{
+		"severity=" + colorGreen + "NOTICE" + colorReset,
+		"severity=" + colorRed + "CRITICAL" + colorReset,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected %q in console output, got: %q", expected, buf.String())
+		}
+	}
+}
+
+func TestColorOutput_ForcedOn(t *testing.T) {
+	oldIsTerminal := isTerminal
+	isTerminal = func(f *os.File) bool { return true }
+	defer func() { isTerminal = oldIsTerminal }()
+	t.Setenv("NO_COLOR", "")
+
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() {
+		os.Stdout = oldStdout
+	}()
+
+	config := DefaultConfig().
+		WithAppName("color-test").
+		WithLogDir("test-logs-color").
+		WithConsoleOutput(true).
+		WithColor(true).
+		WithoutBuffering()
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-color")
+
+	logger.Info("Colored message")
+
+	// Close the pipe writer and read output
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if !strings.Contains(buf.String(), colorGreen+"INFO"+colorReset) {
+		t.Errorf("Expected colored INFO level in console output, got: %q", buf.String())
+	}
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	if strings.Contains(string(content), "\x1b[") {
+		t.Errorf("Expected no color codes in file output, got: %q", content)
+	}
+}
+
+func TestColorOutput_Disabled(t *testing.T) {
+	oldIsTerminal := isTerminal
+	isTerminal = func(f *os.File) bool { return true }
+	defer func() { isTerminal = oldIsTerminal }()
+
+	config := DefaultConfig().WithColor(true)
+
+	t.Setenv("NO_COLOR", "1")
+	if _, ok := config.consoleWriter(os.Stdout).(*colorWriter); ok {
+		t.Error("Expected color to be disabled when NO_COLOR is set")
+	}
+
+	t.Setenv("NO_COLOR", "")
+	if _, ok := config.WithConsoleFormat(FormatJSON).consoleWriter(os.Stdout).(*colorWriter); ok {
+		t.Error("Expected color to be disabled for JSON console format")
+	}
+
+	isTerminal = func(f *os.File) bool { return false }
+	if _, ok := config.consoleWriter(os.Stdout).(*colorWriter); ok {
+		t.Error("Expected color to be disabled when stdout is not a terminal")
+	}
+
+	if _, ok := config.WithColor(false).consoleWriter(os.Stdout).(*colorWriter); ok {
+		t.Error("Expected color to be disabled by default")
+	}
+}
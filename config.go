@@ -1,28 +1,181 @@
 package iSlogger
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"time"
 )
 
+// OutputFormat defines how log records are rendered
+type OutputFormat string
+
+const (
+	FormatText   OutputFormat = "text"   // key=value text output
+	FormatJSON   OutputFormat = "json"   // JSON output
+	FormatGELF   OutputFormat = "gelf"   // Graylog Extended Log Format JSON
+	FormatLogfmt OutputFormat = "logfmt" // Strict logfmt for Loki and other logfmt parsers
+)
+
+// SourceFormat defines how the source location is rendered when AddSource is on
+type SourceFormat string
+
+const (
+	SourceFull    SourceFormat = "full"    // Absolute path and line (default)
+	SourceShort   SourceFormat = "short"   // file.go:42
+	SourcePackage SourceFormat = "package" // pkg/file.go:42
+)
+
+// format renders the source location, or reports false to keep slog's default
+func (f SourceFormat) format(source *slog.Source) (string, bool) {
+	switch f {
+	case SourceShort, SourcePackage:
+		return fmt.Sprintf("%s:%d", f.file(source.File), source.Line), true
+	default:
+		return "", false
+	}
+}
+
+// file shortens the path of a source file
+func (f SourceFormat) file(path string) string {
+	switch f {
+	case SourceShort:
+		return filepath.Base(path)
+	case SourcePackage:
+		return filepath.Base(filepath.Dir(path)) + "/" + filepath.Base(path)
+	default:
+		return path
+	}
+}
+
 type Config struct {
 	LogDir        string     // Directory for log files
 	AppName       string     // Application name for log file prefix
 	LogLevel      slog.Level // Minimum log level (DEBUG, INFO, WARN, ERROR)
 	RetentionDays int        // Number of days to keep log files
 	JSONFormat    bool       // Use JSON format instead of text
+	GELFFormat    bool       // Use GELF format for Graylog, overriding JSONFormat
+	LogfmtFormat  bool       // Use strict logfmt, overriding JSONFormat
 	AddSource     bool       // Add source file and line info
 	TimeFormat    string     // Custom time format
 	ConsoleOutput bool       // Enable output to console (stdout/stderr)
 
+	// Retention configuration
+	MaxTotalSize    int64         // Maximum total size of log files in bytes (0 = unlimited)
+	MaxFiles        int           // Maximum number of log files to keep (0 = unlimited)
+	CleanupInterval time.Duration // How often old log files are cleaned up
+	BackupDir       string        // Directory rotated files are moved to (empty = keep in LogDir)
+
+	// Fallback configuration
+	FallbackToTempDir bool // Log to a directory under os.TempDir if LogDir can't be created or written
+
+	// Per-level files configuration
+	PerLevelFiles     bool // Write DEBUG/INFO/WARN/ERROR to separate files
+	PerLevelDuplicate bool // Also write WARN records to the error file in per-level mode
+	CombinedFile      bool // Write every level to a single file, without the error file
+	CurrentSymlink    bool // Keep undated symlinks (app.log, app_error.log) pointing at the open files
+
+	// Tenant files configuration
+	TenantField  string // Attribute routing records to {AppName}_{value}_{date}.log (empty = disabled)
+	MaxOpenFiles int    // Tenant files kept open, least recently used closed first (0 = 32)
+
+	// Handler configuration
+	HandlerMiddleware []func(slog.Handler) slog.Handler // Wrap the output handlers, between filtering and formatting
+
+	// Rotation configuration
+	RotationHook func(oldPath, newPath string) // Called for each file replaced by a rotation
+	DailySummary bool                          // Write per-level record counts to the outgoing file at midnight
+
+	// Close configuration
+	OnClose []func() error // Called by the first Close or Shutdown after the files are closed, last added first
+
+	// Permissions configuration
+	FileMode os.FileMode // Mode for log files, also applied to existing ones (0 = 0o600, existing files untouched)
+	DirMode  os.FileMode // Mode for log directories, also applied to existing ones (0 = 0o700, existing dirs untouched)
+
+	// File header configuration
+	FileHeader func() string // Returns a line written at the top of every newly created file
+
+	// Registry configuration
+	Register bool // Track the logger so FlushAll and CloseAll reach it
+
+	ConsoleFormat   OutputFormat // Console output format (empty = same as files)
+	Color           bool         // Colorize console level tokens (text format, TTY only)
+	PrettyJSON      bool         // Indent JSON console records (files stay compact)
+	MultilineValues bool         // Write text values containing newlines on indented continuation lines
+	ConsoleTemplate string       // Console line layout, e.g. "{time} [{level}] {msg} {attrs}" (empty = ConsoleFormat)
+	StdErrSplit     bool         // Send each console record to one stream, split at StdErrThreshold
+	StdErrThreshold slog.Level   // With StdErrSplit, levels from here go to stderr and the rest to stdout
+
+	// Binary output configuration
+	BinaryWriter io.Writer    // Receives every record encoded with ProtoEncoder (nil = disabled)
+	ProtoEncoder ProtoEncoder // Encodes records for BinaryWriter (nil = EncodeProtoRecord)
+
 	// Buffering configuration
 	BufferSize    int           // Buffer size in bytes (0 = no buffering)
 	FlushInterval time.Duration // Time interval for automatic buffer flushing
 	FlushOnLevel  slog.Level    // Flush buffer immediately for logs at or above this level
+	MinFlushBytes int           // Periodic flushes skip buffers smaller than this (0 = always flush)
+	MaxFlushAge   time.Duration // Buffered data this old is flushed regardless of MinFlushBytes (0 = 10x FlushInterval)
+	SyncOnError   bool          // Fsync files after every ERROR record (slow, opt-in)
+	FatalExitCode int           // Process exit code used by Fatal (0 = 1)
+
+	// Flush metrics configuration
+	OnFlush func(bytes int, reason FlushReason) // Called after each buffer flush that wrote data
+
+	// Async configuration
+	AsyncQueueSize int            // Records queued for the background writer (0 = write synchronously)
+	AsyncOverflow  OverflowPolicy // What to do when the queue is full (empty = Block)
+
+	// Error handling configuration
+	WriteErrorHandler func(error) // Called when writing to a log file fails
+	StderrFallback    bool        // Re-emit records to stderr when writing to a log file fails
 
 	// Filtering configuration
 	Filters FilterConfig // Filtering and conditional logging configuration
+
+	// Context configuration
+	RespectContextCancellation bool          // Skip records below ERROR logged with an already cancelled context
+	AutoCorrelationID          bool          // WithContext binds the correlation ID carried by the context
+	TraceIDGenerator           func() string // Generates correlation IDs (nil = random 32 hex digits)
+
+	// Stack trace configuration
+	StackTrace      bool       // Attach a stacktrace attribute to records at or above StackTraceLevel
+	StackTraceLevel slog.Level // Minimum level that captures a stack trace
+	ErrorStacks     bool       // Log the stack carried by error attributes with a StackTrace method
+
+	// In-memory tail configuration
+	TailCapacity int // Number of recent records kept in memory (0 = disabled)
+
+	// Reload configuration
+	ReloadPath string // JSON config file reloaded on SIGHUP (empty = disabled)
+
+	// Attribute formatting configuration
+	DurationAsMillis bool                  // Render time.Duration attributes as integer milliseconds
+	LevelNames       map[slog.Level]string // Names for custom levels, or overrides for standard ones
+	SourceFormat     SourceFormat          // How the source location is rendered (empty = full)
+	TimeKey          string                // Key for the record time (empty = "time")
+	LevelKey         string                // Key for the record level (empty = "level")
+	MessageKey       string                // Key for the record message (empty = "msg")
+	ECSFormat        bool                  // Use Elastic Common Schema keys, overriding the keys above
+	GroupSeparator   string                // Flatten groups into keys joined by this separator (empty = nested)
+
+	// Static fields configuration
+	HostField    bool  // Add a host attribute (os.Hostname) to every record
+	PIDField     bool  // Add a pid attribute (os.Getpid) to every record
+	GlobalFields []any // Key-value pairs added to every record, e.g. service and version
+	DedupAttrs   bool  // Chained With calls replace earlier values of the same key
+
+	// Sequence configuration
+	SequenceNumbers bool // Add a seq attribute numbering the records that pass every filter
+
+	// Clock configuration
+	Clock func() time.Time // Time source for record times, file dates and retention (nil = time.Now)
 }
 
 func DefaultConfig() Config {
@@ -39,7 +192,48 @@ func DefaultConfig() Config {
 		FlushInterval: 5 * time.Second, // Flush every 5 seconds
 		FlushOnLevel:  slog.LevelError, // Immediately flush errors
 		Filters:       DefaultFilterConfig(),
+
+		CleanupInterval: 24 * time.Hour, // Clean up old files once a day
+	}
+}
+
+// withDefaults fills in defaults for empty required fields
+func (c Config) withDefaults() Config {
+	if c.LogDir == "" {
+		c.LogDir = "logs"
 	}
+	if c.AppName == "" {
+		c.AppName = "app"
+	}
+	if c.RetentionDays <= 0 {
+		c.RetentionDays = 7
+	}
+	if c.CleanupInterval <= 0 {
+		c.CleanupInterval = 24 * time.Hour
+	}
+	if c.TimeFormat == "" {
+		c.TimeFormat = time.RFC3339
+	}
+	return c
+}
+
+// clone returns a copy of the configuration sharing no maps, or slices that
+// builders append to in place, with c, so building on the copy leaves c unchanged
+func (c Config) clone() Config {
+	c.Filters.Conditions = slices.Clone(c.Filters.Conditions)
+	c.Filters.FieldFilters = maps.Clone(c.Filters.FieldFilters)
+	c.Filters.RegexFilters = slices.Clone(c.Filters.RegexFilters)
+	c.Filters.RateLimits = maps.Clone(c.Filters.RateLimits)
+	c.Filters.TokenBuckets = maps.Clone(c.Filters.TokenBuckets)
+	c.Filters.LevelOverrides = slices.Clone(c.Filters.LevelOverrides)
+	c.LevelNames = maps.Clone(c.LevelNames)
+	return c
+}
+
+// tempLogDir returns the directory used when LogDir is unusable. It is named after
+// the app, so restarts reuse it and retention still applies.
+func (c Config) tempLogDir() string {
+	return filepath.Join(os.TempDir(), c.AppName+"-logs")
 }
 
 // WithLogLevel sets the minimum log level
@@ -60,18 +254,73 @@ func (c Config) WithAppName(name string) Config {
 	return c
 }
 
+// WithFallbackToTempDir makes New log to {os.TempDir}/{AppName}-logs, with a warning
+// naming the configured directory, instead of failing when LogDir can't be created or
+// its files opened, e.g. on a full or read-only disk. Logger.LogDir reports the
+// directory in use.
+func (c Config) WithFallbackToTempDir(enabled bool) Config {
+	c.FallbackToTempDir = enabled
+	return c
+}
+
 // WithRetentionDays sets the retention period
 func (c Config) WithRetentionDays(days int) Config {
 	c.RetentionDays = days
 	return c
 }
 
+// WithMaxTotalSize caps the total size of log files; the oldest are removed first during cleanup
+func (c Config) WithMaxTotalSize(bytes int64) Config {
+	c.MaxTotalSize = bytes
+	return c
+}
+
+// WithMaxFiles keeps at most n log files; the oldest days are removed first during cleanup
+func (c Config) WithMaxFiles(n int) Config {
+	c.MaxFiles = n
+	return c
+}
+
+// WithCleanupInterval sets how often old log files are cleaned up
+func (c Config) WithCleanupInterval(interval time.Duration) Config {
+	c.CleanupInterval = interval
+	return c
+}
+
+// WithBackupDir moves files into dir when they rotate at midnight.
+// Retention then applies to the files in both LogDir and dir.
+func (c Config) WithBackupDir(dir string) Config {
+	c.BackupDir = dir
+	return c
+}
+
+// logDirs returns the directories that hold this logger's files
+func (c Config) logDirs() []string {
+	if c.BackupDir == "" || filepath.Clean(c.BackupDir) == filepath.Clean(c.LogDir) {
+		return []string{c.LogDir}
+	}
+	return []string{c.LogDir, c.BackupDir}
+}
+
 // WithJSONFormat enables JSON format
 func (c Config) WithJSONFormat(json bool) Config {
 	c.JSONFormat = json
 	return c
 }
 
+// WithGELFFormat writes records as GELF messages for Graylog, with user attributes
+// as underscore-prefixed fields
+func (c Config) WithGELFFormat(enabled bool) Config {
+	c.GELFFormat = enabled
+	return c
+}
+
+// WithLogfmtFormat writes records as strict logfmt, quoting only values that need it
+func (c Config) WithLogfmtFormat(enabled bool) Config {
+	c.LogfmtFormat = enabled
+	return c
+}
+
 // WithTimeFormat sets custom time format
 func (c Config) WithTimeFormat(format string) Config {
 	c.TimeFormat = format
@@ -84,12 +333,219 @@ func (c Config) WithAddSource(source bool) Config {
 	return c
 }
 
+// WithSourceFormat sets how the source location is rendered when AddSource is on
+func (c Config) WithSourceFormat(format SourceFormat) Config {
+	c.SourceFormat = format
+	return c
+}
+
 // WithConsoleOutput enables or disables console output
 func (c Config) WithConsoleOutput(console bool) Config {
 	c.ConsoleOutput = console
 	return c
 }
 
+// WithPerLevelFiles writes each level to its own file instead of the info/error split
+func (c Config) WithPerLevelFiles(enabled bool) Config {
+	c.PerLevelFiles = enabled
+	return c
+}
+
+// WithSeparateErrorFile controls the info/error split. With false, a single
+// {AppName}_{date}.log file receives every level and no error file is opened.
+func (c Config) WithSeparateErrorFile(separate bool) Config {
+	c.CombinedFile = !separate
+	return c
+}
+
+// WithCurrentSymlink keeps undated symlinks such as app.log and app_error.log in LogDir
+// pointing at the files currently written, for tools like tail -F. They are updated on
+// every rotation. Where symlinks can't be created (e.g. Windows without privileges) they are skipped.
+func (c Config) WithCurrentSymlink(enabled bool) Config {
+	c.CurrentSymlink = enabled
+	return c
+}
+
+// WithTenantField routes records carrying the key attribute, bound with With or passed
+// to the call, to a file per value: {AppName}_{value}_{date}.log with every level.
+// Records without it go to the regular files. Characters other than letters, digits,
+// '-' and '.' in the value are replaced with '_'.
+func (c Config) WithTenantField(key string) Config {
+	c.TenantField = key
+	return c
+}
+
+// WithMaxOpenFiles bounds the tenant files open at once, so many tenants can't exhaust
+// file descriptors. Beyond it the least recently used file is closed and reopened
+// for appending when its tenant logs again.
+func (c Config) WithMaxOpenFiles(n int) Config {
+	c.MaxOpenFiles = n
+	return c
+}
+
+// WithHandlerMiddleware inserts a handler of your own between filtering and the output
+// handlers, e.g. for custom enrichment. It is applied to both the info and error streams
+// on every rebuild. Middleware added first runs first.
+func (c Config) WithHandlerMiddleware(middleware func(slog.Handler) slog.Handler) Config {
+	c.HandlerMiddleware = append(c.HandlerMiddleware[:len(c.HandlerMiddleware):len(c.HandlerMiddleware)], middleware)
+	return c
+}
+
+// WithRotationHook calls fn for each file replaced by a date rotation, RotateNow or
+// Reconfigure, e.g. to upload the finished file. oldPath is where the previous file
+// now lives (BackupDir if it was archived). fn runs on its own goroutine and may log.
+func (c Config) WithRotationHook(fn func(oldPath, newPath string)) Config {
+	c.RotationHook = fn
+	return c
+}
+
+// WithOnClose adds a callback run by the first Close or Shutdown once the log files
+// are flushed and closed, e.g. to flush a remote output or metrics client. Callbacks
+// run in reverse order of registration and their errors are joined into Close's error.
+func (c Config) WithOnClose(fn func() error) Config {
+	c.OnClose = append(c.OnClose[:len(c.OnClose):len(c.OnClose)], fn)
+	return c
+}
+
+// WithDailySummary writes a final "daily summary" INFO record to the outgoing files
+// at the date rotation, with the day's record count per level
+func (c Config) WithDailySummary(enabled bool) Config {
+	c.DailySummary = enabled
+	return c
+}
+
+// WithPerLevelDuplicate also writes WARN records to the error file in per-level mode
+func (c Config) WithPerLevelDuplicate(enabled bool) Config {
+	c.PerLevelDuplicate = enabled
+	return c
+}
+
+// WithFileHeader writes the line returned by fn at the top of every newly created file,
+// e.g. a JSON object describing the app and schema. Reopened files get no second header.
+func (c Config) WithFileHeader(fn func() string) Config {
+	c.FileHeader = fn
+	return c
+}
+
+// WithFilePermissions sets the mode of log files. Files that already exist are
+// chmod-ed to it when opened, since creating a file is the only time OpenFile sets the mode.
+func (c Config) WithFilePermissions(mode os.FileMode) Config {
+	c.FileMode = mode
+	return c
+}
+
+// WithDirPermissions sets the mode of the log and backup directories, including existing ones
+func (c Config) WithDirPermissions(mode os.FileMode) Config {
+	c.DirMode = mode
+	return c
+}
+
+// fileMode returns the mode new log files are created with
+func (c Config) fileMode() os.FileMode {
+	if c.FileMode == 0 {
+		return 0o600
+	}
+	return c.FileMode
+}
+
+// makeDir creates the directory, applying DirMode to it if one is set
+func (c Config) makeDir(path string) error {
+	mode := c.DirMode
+	if mode == 0 {
+		return os.MkdirAll(path, 0o700)
+	}
+	if err := os.MkdirAll(path, mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
+// WithRegister tracks the logger in the package registry, so FlushAll and
+// CloseAll flush and close it on shutdown
+func (c Config) WithRegister(register bool) Config {
+	c.Register = register
+	return c
+}
+
+// WithConsoleFormat sets the console output format independently of the file format
+func (c Config) WithConsoleFormat(format OutputFormat) Config {
+	c.ConsoleFormat = format
+	return c
+}
+
+// WithColor enables ANSI colored levels in console text output.
+// Colors are skipped when the console is not a terminal or NO_COLOR is set.
+func (c Config) WithColor(color bool) Config {
+	c.Color = color
+	return c
+}
+
+// WithMultilineValues writes string values containing newlines, such as stack
+// traces, on indented lines below the record instead of escaping them. It applies
+// to text files and console output; JSON and the other formats are unaffected.
+func (c Config) WithMultilineValues(enabled bool) Config {
+	c.MultilineValues = enabled
+	return c
+}
+
+// WithStdErrThreshold sends console records at or above level to stderr and the rest
+// to stdout, each to exactly one stream. By default stdout gets every level and
+// stderr also gets WARN and above.
+func (c Config) WithStdErrThreshold(level slog.Level) Config {
+	c.StdErrSplit = true
+	c.StdErrThreshold = level
+	return c
+}
+
+// WithConsoleTemplate lays out console lines with a template of {time}, {level},
+// {msg}, {attrs} and {source} placeholders, e.g. "{time} [{level}] {msg} {attrs}".
+// It takes precedence over ConsoleFormat; files keep their format.
+func (c Config) WithConsoleTemplate(template string) Config {
+	c.ConsoleTemplate = template
+	return c
+}
+
+// WithBinaryWriter sends every record, encoded as length-prefixed protobuf, to w,
+// e.g. a connection to a custom collector. Files are unaffected.
+func (c Config) WithBinaryWriter(w io.Writer) Config {
+	c.BinaryWriter = w
+	return c
+}
+
+// WithProtoEncoder replaces the encoding of records sent to the binary writer
+func (c Config) WithProtoEncoder(encode ProtoEncoder) Config {
+	c.ProtoEncoder = encode
+	return c
+}
+
+// fileFormat returns the format used for log files
+func (c Config) fileFormat() OutputFormat {
+	if c.GELFFormat {
+		return FormatGELF
+	}
+	if c.LogfmtFormat {
+		return FormatLogfmt
+	}
+	if c.JSONFormat {
+		return FormatJSON
+	}
+	return FormatText
+}
+
+// consoleFormat returns the format used for console output
+func (c Config) consoleFormat() OutputFormat {
+	if c.ConsoleFormat == "" {
+		return c.fileFormat()
+	}
+	return c.ConsoleFormat
+}
+
+// WithPrettyJSON indents JSON console records for local debugging; files stay compact
+func (c Config) WithPrettyJSON(pretty bool) Config {
+	c.PrettyJSON = pretty
+	return c
+}
+
 // Filtering configuration methods
 
 // WithCondition adds a conditional logging function
@@ -140,6 +596,109 @@ func (c Config) WithRateLimit(level slog.Level, maxCount int, period time.Durati
 		MaxCount: maxCount,
 		Period:   period,
 	}
+	delete(c.Filters.TokenBuckets, level)
+	return c
+}
+
+// WithGlobalRateLimit caps the total number of records per period regardless of level
+func (c Config) WithGlobalRateLimit(maxCount int, period time.Duration) Config {
+	c.Filters.GlobalRateLimit = RateLimit{
+		MaxCount: maxCount,
+		Period:   period,
+	}
+	return c
+}
+
+// WithRateLimitByMessage limits every distinct message to maxCount records per period,
+// so one repeating message can't crowd out the rest of its level
+func (c Config) WithRateLimitByMessage(maxCount int, period time.Duration) Config {
+	c.Filters.MessageRateLimit = RateLimit{
+		MaxCount: maxCount,
+		Period:   period,
+	}
+	return c
+}
+
+// WithAdaptiveSampling samples DEBUG and INFO records once their rate exceeds
+// targetPerSecond, keeping roughly that many per second and easing off as the rate
+// falls. WARN and ERROR records are never sampled.
+func (c Config) WithAdaptiveSampling(targetPerSecond int) Config {
+	c.Filters.SamplingTarget = targetPerSecond
+	return c
+}
+
+// WithGlobalRateLimitErrorBypass lets ERROR records bypass the global rate limit
+func (c Config) WithGlobalRateLimitErrorBypass(bypass bool) Config {
+	c.Filters.GlobalBypassErrors = bypass
+	return c
+}
+
+// WithTokenBucketRateLimit shapes logs for a specific level with a token bucket
+// that refills at rate tokens per second up to burst, replacing any window limit
+func (c Config) WithTokenBucketRateLimit(level slog.Level, rate float64, burst int) Config {
+	if c.Filters.TokenBuckets == nil {
+		c.Filters.TokenBuckets = make(map[slog.Level]TokenBucket)
+	}
+	c.Filters.TokenBuckets[level] = TokenBucket{
+		Rate:  rate,
+		Burst: burst,
+	}
+	delete(c.Filters.RateLimits, level)
+	return c
+}
+
+// WithDropEmptyAttrs drops attributes whose value is nil, an empty string or an empty group
+func (c Config) WithDropEmptyAttrs(enabled bool) Config {
+	c.Filters.DropEmptyAttrs = enabled
+	return c
+}
+
+// WithSanitizeUTF8 replaces each invalid UTF-8 sequence in string attribute values,
+// e.g. from raw network data, with a single U+FFFD so every output format gets the
+// same valid text
+func (c Config) WithSanitizeUTF8(enabled bool) Config {
+	c.Filters.SanitizeUTF8 = enabled
+	return c
+}
+
+// WithMaxValueLength truncates string attribute values longer than n bytes, inside groups too (0 disables it)
+func (c Config) WithMaxValueLength(n int) Config {
+	c.Filters.MaxValueLength = n
+	return c
+}
+
+// WithAttrSort orders each record's attributes, e.g. SortAlpha or
+// SortByPriority([]string{"request_id"}). Attributes bound with With stay in
+// front of the record's and are sorted within each With call, so a request_id
+// bound by the HTTP middleware comes first.
+func (c Config) WithAttrSort(sort AttrSort) Config {
+	c.Filters.AttrSort = sort
+	return c
+}
+
+// WithMaxAttrs keeps at most n attributes per record (0 disables it).
+// Attributes bound with With do not count toward the limit.
+func (c Config) WithMaxAttrs(n int) Config {
+	c.Filters.MaxAttrs = n
+	return c
+}
+
+// WithLevelOverride logs records matching cond from level upwards, even below the
+// logger's level. It never raises the threshold for other records.
+func (c Config) WithLevelOverride(cond LogCondition, level slog.Level) Config {
+	c.Filters.LevelOverrides = append(c.Filters.LevelOverrides, LevelOverride{
+		Condition: cond,
+		Level:     level,
+	})
+	return c
+}
+
+// WithPackageFilter filters records by the package of the code that logged them:
+// records from deny are dropped and, if allow is not empty, only records from
+// allow are kept. Paths are import paths and also cover their subpackages.
+func (c Config) WithPackageFilter(allow, deny []string) Config {
+	c.Filters.AllowPackages = allow
+	c.Filters.DenyPackages = deny
 	return c
 }
 
@@ -158,11 +717,21 @@ func (c Config) WithAttributeCondition(key, value string) Config {
 	return c.WithCondition(AttributeCondition(key, value))
 }
 
+// WithAttributePresentCondition adds a condition that requires the attribute key to be present
+func (c Config) WithAttributePresentCondition(key string) Config {
+	return c.WithCondition(AttributePresentCondition(key))
+}
+
 // WithTimeBasedCondition adds a time-based condition
 func (c Config) WithTimeBasedCondition(startHour, endHour int) Config {
 	return c.WithCondition(TimeBasedCondition(startHour, endHour))
 }
 
+// WithWeekdayCondition adds a condition that only logs on the given days of the week
+func (c Config) WithWeekdayCondition(days ...time.Weekday) Config {
+	return c.WithCondition(WeekdayCondition(days...))
+}
+
 // Buffering configuration methods
 
 // WithBufferSize sets the buffer size in bytes (0 disables buffering)
@@ -183,6 +752,92 @@ func (c Config) WithFlushOnLevel(level slog.Level) Config {
 	return c
 }
 
+// WithMinFlushBytes makes the periodic flush skip buffers holding fewer than n bytes,
+// avoiding tiny writes under a short FlushInterval. Data is still flushed once it
+// reaches MaxFlushAge, so latency stays bounded.
+func (c Config) WithMinFlushBytes(n int) Config {
+	c.MinFlushBytes = n
+	return c
+}
+
+// WithMaxFlushAge sets how long buffered data may wait for MinFlushBytes before
+// the periodic flush writes it anyway
+func (c Config) WithMaxFlushAge(age time.Duration) Config {
+	c.MaxFlushAge = age
+	return c
+}
+
+// WithAsync writes records on a background goroutine through a queue of queueSize records,
+// so a slow disk doesn't stall callers. Close, Flush and Sync wait for the queue to drain.
+// The queue is created by New and is not changed by Reconfigure.
+func (c Config) WithAsync(queueSize int) Config {
+	c.AsyncQueueSize = queueSize
+	return c
+}
+
+// WithAsyncOverflow sets what happens when the async queue is full
+func (c Config) WithAsyncOverflow(policy OverflowPolicy) Config {
+	c.AsyncOverflow = policy
+	return c
+}
+
+// WithRespectContextCancellation skips records below ERROR logged with a context
+// that is already cancelled or past its deadline, e.g. for abandoned requests.
+// It applies to Log and to the context methods of Slog and Handler.
+func (c Config) WithRespectContextCancellation(enabled bool) Config {
+	c.RespectContextCancellation = enabled
+	return c
+}
+
+// WithAutoCorrelationID makes WithContext bind the correlation ID carried by the
+// context as a correlation_id attribute. Contexts get an ID from ContextWithCorrelationID
+// or WithCorrelationID, which generate one if missing.
+func (c Config) WithAutoCorrelationID(enabled bool) Config {
+	c.AutoCorrelationID = enabled
+	return c
+}
+
+// WithTraceIDGenerator sets the function generating missing correlation IDs
+func (c Config) WithTraceIDGenerator(generate func() string) Config {
+	c.TraceIDGenerator = generate
+	return c
+}
+
+// WithBufferFlushMetrics calls fn after every flush of a file buffer with the number
+// of bytes written and why, e.g. to export flush counts and sizes. fn runs with the
+// buffer locked, so it must not log to the same logger.
+func (c Config) WithBufferFlushMetrics(fn func(bytes int, reason FlushReason)) Config {
+	c.OnFlush = fn
+	return c
+}
+
+// WithSyncOnError fsyncs the log files after every ERROR record.
+// This makes critical events durable at a significant performance cost.
+func (c Config) WithSyncOnError(enabled bool) Config {
+	c.SyncOnError = enabled
+	return c
+}
+
+// WithFatalExitCode sets the process exit code used by Fatal
+func (c Config) WithFatalExitCode(code int) Config {
+	c.FatalExitCode = code
+	return c
+}
+
+// WithWriteErrorHandler sets a callback invoked when writing to a log file fails,
+// e.g. when the directory became read-only. The callback must not log through
+// the same logger.
+func (c Config) WithWriteErrorHandler(fn func(error)) Config {
+	c.WriteErrorHandler = fn
+	return c
+}
+
+// WithStderrFallback re-emits records to stderr when writing to a log file fails
+func (c Config) WithStderrFallback(enabled bool) Config {
+	c.StderrFallback = enabled
+	return c
+}
+
 // WithBuffering enables buffering with default settings
 func (c Config) WithBuffering() Config {
 	c.BufferSize = 8192
@@ -196,3 +851,190 @@ func (c Config) WithoutBuffering() Config {
 	c.BufferSize = 0
 	return c
 }
+
+// WithStackTrace attaches the caller stack to records at or above minLevel
+func (c Config) WithStackTrace(minLevel slog.Level) Config {
+	c.StackTrace = true
+	c.StackTraceLevel = minLevel
+	return c
+}
+
+// WithErrorStacks adds the stack of error attributes whose chain has a StackTrace
+// method, as errors from github.com/pkg/errors do, as the stacktrace attribute.
+// It shows where the error was created and takes precedence over WithStackTrace.
+func (c Config) WithErrorStacks(enabled bool) Config {
+	c.ErrorStacks = enabled
+	return c
+}
+
+// WithInMemoryTail keeps the last capacity formatted records in memory (0 disables it)
+func (c Config) WithInMemoryTail(capacity int) Config {
+	c.TailCapacity = capacity
+	return c
+}
+
+// WithSignalReload reloads the JSON config file at path on SIGHUP
+func (c Config) WithSignalReload(path string) Config {
+	c.ReloadPath = path
+	return c
+}
+
+// WithDurationAsMillis renders time.Duration attributes as integer milliseconds
+func (c Config) WithDurationAsMillis(enabled bool) Config {
+	c.DurationAsMillis = enabled
+	return c
+}
+
+// WithLevelNames renders the given levels with custom names, e.g. slog.Level(12) as CRITICAL
+func (c Config) WithLevelNames(names map[slog.Level]string) Config {
+	c.LevelNames = names
+	return c
+}
+
+// WithTimeKey renames the time key, e.g. to "@timestamp" for ELK. It turns ECS format off.
+func (c Config) WithTimeKey(name string) Config {
+	c.TimeKey = name
+	c.ECSFormat = false
+	return c
+}
+
+// WithLevelKey renames the level key, e.g. to "severity". It turns ECS format off.
+func (c Config) WithLevelKey(name string) Config {
+	c.LevelKey = name
+	c.ECSFormat = false
+	return c
+}
+
+// WithMessageKey renames the message key, e.g. to "message" for Datadog. It turns ECS format off.
+func (c Config) WithMessageKey(name string) Config {
+	c.MessageKey = name
+	c.ECSFormat = false
+	return c
+}
+
+// WithECSFormat writes JSON records with Elastic Common Schema keys: @timestamp,
+// log.level, message and log.origin for the source. It replaces any custom key names.
+func (c Config) WithECSFormat(enabled bool) Config {
+	c.ECSFormat = enabled
+	if enabled {
+		c.JSONFormat = true
+		c.TimeKey, c.LevelKey, c.MessageKey = "", "", ""
+	}
+	return c
+}
+
+// WithFlattenGroups writes grouped attributes as flat keys joined by separator,
+// e.g. user.id instead of a nested user object. An empty separator keeps groups nested.
+func (c Config) WithFlattenGroups(separator string) Config {
+	c.GroupSeparator = separator
+	return c
+}
+
+// ecsKeys maps slog's built-in keys to their Elastic Common Schema names
+var ecsKeys = map[string]string{
+	slog.TimeKey:    "@timestamp",
+	slog.LevelKey:   "log.level",
+	slog.MessageKey: "message",
+}
+
+// ecsOrigin renders the source location as the ECS log.origin object
+func (c Config) ecsOrigin(source *slog.Source) slog.Attr {
+	return slog.Group("log.origin",
+		slog.Group("file",
+			slog.String("name", c.SourceFormat.file(source.File)),
+			slog.Int("line", source.Line),
+		),
+		slog.String("function", source.Function),
+	)
+}
+
+// builtinKey returns the configured name for one of slog's built-in keys
+func (c Config) builtinKey(key string) string {
+	if c.ECSFormat {
+		if name, ok := ecsKeys[key]; ok {
+			return name
+		}
+		return key
+	}
+
+	var name string
+	switch key {
+	case slog.TimeKey:
+		name = c.TimeKey
+	case slog.LevelKey:
+		name = c.LevelKey
+	case slog.MessageKey:
+		name = c.MessageKey
+	}
+	if name == "" {
+		return key
+	}
+	return name
+}
+
+// WithHostField adds a host attribute with the machine's hostname to every record
+func (c Config) WithHostField(enabled bool) Config {
+	c.HostField = enabled
+	return c
+}
+
+// WithPIDField adds a pid attribute with the process ID to every record
+func (c Config) WithPIDField(enabled bool) Config {
+	c.PIDField = enabled
+	return c
+}
+
+// WithSequenceNumbers adds a seq attribute counting up by one per written record.
+// Records are numbered after filtering and rate limiting, so a gap in a collector
+// means records were lost on the way. Derived loggers share their parent's counter.
+func (c Config) WithSequenceNumbers(enabled bool) Config {
+	c.SequenceNumbers = enabled
+	return c
+}
+
+// WithGlobalFields adds constant key-value pairs, such as service, version or env,
+// to every record. They persist across rotation and loggers derived with With.
+func (c Config) WithGlobalFields(args ...any) Config {
+	c.GlobalFields = append(c.GlobalFields[:len(c.GlobalFields):len(c.GlobalFields)], args...)
+	return c
+}
+
+// WithDedupAttrs makes With replace attributes bound earlier under the same key,
+// so With("user_id", 1).With("user_id", 2) writes only user_id=2
+func (c Config) WithDedupAttrs(dedup bool) Config {
+	c.DedupAttrs = dedup
+	return c
+}
+
+// WithClock sets the time source used for record times, file dates, rotation and
+// retention. Rate limits keep measuring real time, and TimeBasedCondition and
+// WeekdayCondition keep reading the real clock, as conditions don't know their logger.
+// It is meant for tests that need to simulate a date change.
+func (c Config) WithClock(clock func() time.Time) Config {
+	c.Clock = clock
+	return c
+}
+
+// now returns the current time from the configured clock
+func (c Config) now() time.Time {
+	if c.Clock != nil {
+		return c.Clock()
+	}
+	return nowFunc()
+}
+
+// originAttrs resolves the host and pid attributes enabled in the config
+func (c Config) originAttrs() []slog.Attr {
+	var attrs []slog.Attr
+	if c.HostField {
+		host, err := os.Hostname()
+		if err != nil {
+			host = "unknown"
+		}
+		attrs = append(attrs, slog.String("host", host))
+	}
+	if c.PIDField {
+		attrs = append(attrs, slog.Int("pid", os.Getpid()))
+	}
+	return attrs
+}
@@ -0,0 +1,130 @@
+package iSlogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// fileConfig is the JSON representation of Config loaded by LoadConfig.
+// Fields left out of the file keep their current values.
+type fileConfig struct {
+	LogDir          *string       `json:"log_dir"`
+	AppName         *string       `json:"app_name"`
+	LogLevel        *slog.Level   `json:"log_level"`
+	RetentionDays   *int          `json:"retention_days"`
+	MaxTotalSize    *int64        `json:"max_total_size"`
+	MaxFiles        *int          `json:"max_files"`
+	CleanupInterval *string       `json:"cleanup_interval"`
+	JSONFormat      *bool         `json:"json_format"`
+	AddSource       *bool         `json:"add_source"`
+	TimeFormat      *string       `json:"time_format"`
+	ConsoleOutput   *bool         `json:"console_output"`
+	ConsoleFormat   *OutputFormat `json:"console_format"`
+	Color           *bool         `json:"color"`
+	BufferSize      *int          `json:"buffer_size"`
+	FlushInterval   *string       `json:"flush_interval"`
+	FlushOnLevel    *slog.Level   `json:"flush_on_level"`
+	TailCapacity    *int          `json:"tail_capacity"`
+}
+
+// LoadConfig reads a JSON config file on top of DefaultConfig.
+// Filters, conditions and other code-only options are not loaded from the file.
+func LoadConfig(path string) (Config, error) {
+	return loadConfigFile(DefaultConfig(), path)
+}
+
+// loadConfigFile reads a JSON config file on top of the given base config
+func loadConfigFile(base Config, path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return base, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var fc fileConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fc); err != nil {
+		return base, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	return fc.apply(base)
+}
+
+// apply overrides the base config with the values present in the file
+func (fc fileConfig) apply(c Config) (Config, error) {
+	if fc.LogDir != nil {
+		c.LogDir = *fc.LogDir
+	}
+	if fc.AppName != nil {
+		c.AppName = *fc.AppName
+	}
+	if fc.LogLevel != nil {
+		c.LogLevel = *fc.LogLevel
+	}
+	if fc.RetentionDays != nil {
+		c.RetentionDays = *fc.RetentionDays
+	}
+	if fc.MaxTotalSize != nil {
+		c.MaxTotalSize = *fc.MaxTotalSize
+	}
+	if fc.MaxFiles != nil {
+		c.MaxFiles = *fc.MaxFiles
+	}
+	if fc.CleanupInterval != nil {
+		interval, err := time.ParseDuration(*fc.CleanupInterval)
+		if err != nil {
+			return c, fmt.Errorf("invalid cleanup_interval: %w", err)
+		}
+		c.CleanupInterval = interval
+	}
+	if fc.JSONFormat != nil {
+		c.JSONFormat = *fc.JSONFormat
+	}
+	if fc.AddSource != nil {
+		c.AddSource = *fc.AddSource
+	}
+	if fc.TimeFormat != nil {
+		c.TimeFormat = *fc.TimeFormat
+	}
+	if fc.ConsoleOutput != nil {
+		c.ConsoleOutput = *fc.ConsoleOutput
+	}
+	if fc.ConsoleFormat != nil {
+		switch *fc.ConsoleFormat {
+		case "", FormatText, FormatJSON, FormatGELF, FormatLogfmt:
+			c.ConsoleFormat = *fc.ConsoleFormat
+		default:
+			return c, fmt.Errorf("invalid console_format: %q", *fc.ConsoleFormat)
+		}
+	}
+	if fc.Color != nil {
+		c.Color = *fc.Color
+	}
+	if fc.BufferSize != nil {
+		if *fc.BufferSize < 0 {
+			return c, fmt.Errorf("invalid buffer_size: %d", *fc.BufferSize)
+		}
+		c.BufferSize = *fc.BufferSize
+	}
+	if fc.FlushInterval != nil {
+		interval, err := time.ParseDuration(*fc.FlushInterval)
+		if err != nil {
+			return c, fmt.Errorf("invalid flush_interval: %w", err)
+		}
+		c.FlushInterval = interval
+	}
+	if fc.FlushOnLevel != nil {
+		c.FlushOnLevel = *fc.FlushOnLevel
+	}
+	if fc.TailCapacity != nil {
+		if *fc.TailCapacity < 0 {
+			return c, fmt.Errorf("invalid tail_capacity: %d", *fc.TailCapacity)
+		}
+		c.TailCapacity = *fc.TailCapacity
+	}
+	return c, nil
+}
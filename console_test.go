@@ -111,3 +111,206 @@ func TestWithConsoleOutput(t *testing.T) {
 		t.Errorf("Expected original config to remain unchanged")
 	}
 }
+
+func TestConsoleFormat_SplitFromFileFormat(t *testing.T) {
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() {
+		os.Stdout = oldStdout
+	}()
+
+	config := DefaultConfig().
+		WithAppName("console-format").
+		WithLogDir("test-logs-console-format").
+		WithJSONFormat(true).
+		WithConsoleOutput(true).
+		WithConsoleFormat(FormatText).
+		WithoutBuffering()
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-console-format")
+
+	logger.Info("Split format message", "key", "value")
+
+	// Close the pipe writer and read output
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	output := buf.String()
+
+	if !strings.Contains(output, `msg="Split format message"`) || !strings.Contains(output, "key=value") {
+		t.Errorf("Expected text console output, got: %s", output)
+	}
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	if !strings.Contains(string(content), `"msg":"Split format message"`) || !strings.Contains(string(content), `"key":"value"`) {
+		t.Errorf("Expected JSON file output, got: %s", content)
+	}
+}
+
+func TestWithConsoleFormat(t *testing.T) {
+	config := DefaultConfig().WithJSONFormat(true)
+
+	if config.consoleFormat() != FormatJSON {
+		t.Errorf("Expected console format to follow file format, got %q", config.consoleFormat())
+	}
+
+	config = config.WithConsoleFormat(FormatText)
+	if config.consoleFormat() != FormatText {
+		t.Errorf("Expected console format %q, got %q", FormatText, config.consoleFormat())
+	}
+	if config.fileFormat() != FormatJSON {
+		t.Errorf("Expected file format to stay %q, got %q", FormatJSON, config.fileFormat())
+	}
+}
+
+func TestPrettyJSON(t *testing.T) {
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() {
+		os.Stdout = oldStdout
+	}()
+
+	config := DefaultConfig().
+		WithAppName("console-pretty").
+		WithLogDir("test-logs-console-pretty").
+		WithJSONFormat(true).
+		WithConsoleOutput(true).
+		WithPrettyJSON(true).
+		WithoutBuffering()
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-console-pretty")
+
+	logger.Info("Pretty message", "key", "value")
+
+	// Close the pipe writer and read output
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	output := buf.String()
+
+	if !strings.Contains(output, "{\n  \"time\"") || !strings.Contains(output, "\n  \"key\": \"value\"\n}") {
+		t.Errorf("Expected indented console output, got: %s", output)
+	}
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	if lines := strings.Count(string(content), "\n"); lines != 1 || !strings.Contains(string(content), `"msg":"Pretty message"`) {
+		t.Errorf("Expected a single compact line in the file, got: %s", content)
+	}
+}
+
+func TestConsoleTemplate(t *testing.T) {
+	// Capture stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() {
+		os.Stdout = oldStdout
+	}()
+
+	config := DefaultConfig().
+		WithAppName("template-test").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(true).
+		WithoutBuffering().
+		WithTimeFormat("15:04:05").
+		WithConsoleTemplate("[{level}] {msg} | {attrs} @ {time}")
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.With("service", "api").Info("Order placed", "order", 42, "note", "two words")
+
+	// Close the pipe writer and read output
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	output := buf.String()
+
+	prefix := `[INFO] Order placed | service=api order=42 note="two words" @ `
+	if !strings.HasPrefix(output, prefix) || len(output) != len(prefix)+len("15:04:05\n") {
+		t.Errorf("Expected console output to follow the template, got: %q", output)
+	}
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	if !strings.Contains(string(content), `level=INFO msg="Order placed" service=api order=42`) {
+		t.Errorf("Expected the file to keep the standard format, got: %s", content)
+	}
+}
+
+func TestStdErrThreshold(t *testing.T) {
+	capture := func(config Config) (stdout, stderr string) {
+		t.Helper()
+		oldStdout, oldStderr := os.Stdout, os.Stderr
+		outR, outW, _ := os.Pipe()
+		errR, errW, _ := os.Pipe()
+		os.Stdout, os.Stderr = outW, errW
+		defer func() {
+			os.Stdout, os.Stderr = oldStdout, oldStderr
+		}()
+
+		logger, err := New(config.WithAppName("console-stderr").WithLogDir(t.TempDir()))
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		logger.Info("info record")
+		logger.Warn("warn record")
+		logger.Error("error record")
+		logger.Close()
+
+		outW.Close()
+		errW.Close()
+		var outBuf, errBuf bytes.Buffer
+		outBuf.ReadFrom(outR)
+		errBuf.ReadFrom(errR)
+		return outBuf.String(), errBuf.String()
+	}
+
+	stdout, stderr := capture(DefaultConfig().WithStdErrThreshold(slog.LevelWarn))
+	if !strings.Contains(stdout, "info record") || strings.Contains(stdout, "warn record") || strings.Contains(stdout, "error record") {
+		t.Errorf("Expected only INFO on stdout, got: %s", stdout)
+	}
+	if strings.Contains(stderr, "info record") || strings.Count(stderr, "warn record") != 1 || !strings.Contains(stderr, "error record") {
+		t.Errorf("Expected WARN once and ERROR on stderr, got: %s", stderr)
+	}
+
+	// A higher threshold keeps warnings on stdout
+	stdout, stderr = capture(DefaultConfig().WithStdErrThreshold(slog.LevelError))
+	if !strings.Contains(stdout, "warn record") || strings.Contains(stderr, "warn record") {
+		t.Errorf("Expected WARN only on stdout, got stdout: %s stderr: %s", stdout, stderr)
+	}
+
+	// By default warnings appear on both streams
+	stdout, stderr = capture(DefaultConfig())
+	if !strings.Contains(stdout, "warn record") || !strings.Contains(stderr, "warn record") {
+		t.Errorf("Expected WARN on both streams by default, got stdout: %s stderr: %s", stdout, stderr)
+	}
+}
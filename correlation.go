@@ -0,0 +1,66 @@
+package iSlogger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// CorrelationIDKey is the attribute key of correlation IDs bound by WithContext
+const CorrelationIDKey = "correlation_id"
+
+// correlationIDContextKey is the context key type for correlation IDs
+type correlationIDContextKey struct{}
+
+// ContextWithCorrelationID returns ctx carrying a correlation ID, keeping the one
+// already in ctx or generating a random one. Call it where a request enters the service
+// so every logger built with WithContext down the line shares the ID. Use the
+// Logger method of the same name to generate with the configured TraceIDGenerator.
+func ContextWithCorrelationID(ctx context.Context) context.Context {
+	if _, ok := CorrelationIDFromContext(ctx); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationIDContextKey{}, newCorrelationID())
+}
+
+// CorrelationIDFromContext returns the correlation ID carried by ctx
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationIDContextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// newCorrelationID generates a random 16 byte ID in hex
+func newCorrelationID() string {
+	var b [16]byte
+	rand.Read(b[:]) // Never returns an error
+	return hex.EncodeToString(b[:])
+}
+
+// correlationID returns the ID carried by ctx, adding one from the configured
+// generator if there is none
+func (c Config) correlationID(ctx context.Context) (string, context.Context) {
+	if id, ok := CorrelationIDFromContext(ctx); ok {
+		return id, ctx
+	}
+	generate := c.TraceIDGenerator
+	if generate == nil {
+		generate = newCorrelationID
+	}
+	id := generate()
+	return id, context.WithValue(ctx, correlationIDContextKey{}, id)
+}
+
+// ContextWithCorrelationID returns ctx carrying a correlation ID, keeping the one
+// already in ctx or adding one from the configured TraceIDGenerator
+func (l *Logger) ContextWithCorrelationID(ctx context.Context) context.Context {
+	_, ctx = l.currentConfig().correlationID(ctx)
+	return ctx
+}
+
+// WithCorrelationID binds the correlation ID carried by ctx, generating one if
+// there is none, and returns the logger along with the context carrying the ID
+// for passing on to callees.
+func (l *Logger) WithCorrelationID(ctx context.Context) (*Logger, context.Context) {
+	id, ctx := l.currentConfig().correlationID(ctx)
+	return l.With(CorrelationIDKey, id), ctx
+}
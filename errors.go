@@ -0,0 +1,30 @@
+package iSlogger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WithError creates a logger with structured error attributes:
+// error (message), error_type (concrete type) and, for wrapped errors,
+// error_cause (messages of the unwrapped chain, outermost first)
+func (l *Logger) WithError(err error) *Logger {
+	if err == nil {
+		return l
+	}
+
+	args := []any{
+		"error", err.Error(),
+		"error_type", fmt.Sprintf("%T", err),
+	}
+
+	var causes []string
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		causes = append(causes, cause.Error())
+	}
+	if len(causes) > 0 {
+		args = append(args, "error_cause", causes)
+	}
+
+	return l.With(args...)
+}
@@ -0,0 +1,53 @@
+package iSlogger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWithError(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-with-error").
+		WithLogDir("test-logs-with-error").
+		WithConsoleOutput(false).
+		WithoutBuffering()
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-with-error")
+
+	logger.WithError(errors.New("plain failure")).Error("Plain error")
+
+	root := os.ErrNotExist
+	wrapped := fmt.Errorf("load config: %w", fmt.Errorf("open file: %w", root))
+	logger.WithError(wrapped).Error("Wrapped error")
+
+	if logger.WithError(nil) != logger {
+		t.Error("Expected WithError(nil) to return the same logger")
+	}
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	output := string(content)
+
+	if !strings.Contains(output, `msg="Plain error" error="plain failure" error_type=*errors.errorString`) {
+		t.Errorf("Expected plain error attributes, got: %s", output)
+	}
+	if strings.Contains(strings.Split(output, "\n")[0], "error_cause") {
+		t.Errorf("Expected no error_cause for unwrapped error, got: %s", output)
+	}
+
+	expected := `msg="Wrapped error" error="load config: open file: file does not exist" error_type=*fmt.wrapError error_cause="[open file: file does not exist file does not exist]"`
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected wrapped error attributes %s, got: %s", expected, output)
+	}
+}
@@ -17,7 +17,8 @@ func main() {
 		WithLogDir("advanced-logs").
 		WithRetentionDays(14).
 		WithJSONFormat(true).
-		WithTimeFormat("2006-01-02 15:04:05")
+		WithTimeFormat("2006-01-02 15:04:05").
+		WithDedupAttrs(true) // Later With values replace earlier ones
 
 	// Create multiple logger instances
 	logger1, err := iSlogger.New(config)
@@ -41,10 +42,12 @@ func main() {
 	logger1.Info("Logger 1 started", "format", "JSON")
 	logger2.Info("Logger 2 started", "format", "Text")
 
-	// Context-aware logging
-	ctx := context.WithValue(context.Background(), "request_id", "req-12345")
-	ctxLogger := logger1.WithContext(ctx)
+	// Context-aware logging: the correlation ID travels in ctx to callees
+	ctxLogger, ctx := logger1.WithCorrelationID(context.Background())
 	ctxLogger.Info("Processing request", "operation", "user_creation")
+	if id, ok := iSlogger.CorrelationIDFromContext(ctx); ok {
+		fmt.Println("Correlation ID:", id)
+	}
 
 	// Chained context building
 	sessionLogger := logger1.
@@ -55,6 +58,9 @@ func main() {
 	sessionLogger.Info("User session started")
 	sessionLogger.Warn("Suspicious activity detected", "reason", "multiple_failed_logins")
 
+	// Re-binding a key replaces it instead of writing user_id twice
+	sessionLogger.With("user_id", 43).Info("User switched account")
+
 	// Dynamic level switching
 	logger1.Info("Current level: Debug")
 	logger1.SetLevel(slog.LevelError) // Only errors will be logged
@@ -90,7 +96,11 @@ func main() {
 
 	// Manual cleanup trigger
 	logger1.Info("Triggering cleanup...")
-	logger1.CleanupNow()
+	if removed, err := logger1.Cleanup(); err != nil {
+		logger1.Error("Cleanup failed", "error", err)
+	} else {
+		logger1.Info("Cleanup completed", "removed", len(removed))
+	}
 
 	// Performance logging example
 	start := time.Now()
@@ -99,17 +109,14 @@ func main() {
 
 	logger1.Info("Operation completed",
 		"operation", "simulate_work",
-		"duration_ms", duration.Milliseconds(),
-		"duration_str", duration.String(),
+		iSlogger.Duration("duration_ms", duration),
 		"success", true,
 	)
 
 	// Error handling with stack context
 	if err := simulateError(); err != nil {
-		logger1.Error("Operation failed",
+		logger1.WithError(err).Error("Operation failed",
 			"operation", "simulate_error",
-			"error", err.Error(),
-			"error_type", fmt.Sprintf("%T", err),
 			"retry_recommended", true,
 		)
 	}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/sarff/iSlogger"
+	isloggerhttp "github.com/sarff/iSlogger/http"
 )
 
 type User struct {
@@ -30,12 +31,13 @@ func main() {
 		WithAppName("webapp").
 		WithLogLevel(slog.LevelWarn). // Production mode
 		WithLogDir("web-logs").
-		WithJSONFormat(true) // JSON format for log aggregation
+		WithJSONFormat(true). // JSON format for log aggregation
+		WithInMemoryTail(100) // Keep recent records for the /logs endpoint
 
 	if err := iSlogger.Init(config); err != nil {
 		panic(fmt.Sprintf("Failed to initialize logger: %v", err))
 	}
-	defer iSlogger.Close()
+	defer iSlogger.Close() // Safety net; Shutdown below drains pending records first
 
 	// Create server instance
 	server := &Server{
@@ -44,15 +46,16 @@ func main() {
 
 	// Setup HTTP routes
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", server.loggingMiddleware(server.homeHandler))
-	mux.HandleFunc("/users", server.loggingMiddleware(server.usersHandler))
-	mux.HandleFunc("/users/", server.loggingMiddleware(server.userHandler))
-	mux.HandleFunc("/health", server.loggingMiddleware(server.healthHandler))
+	mux.HandleFunc("/", server.homeHandler)
+	mux.HandleFunc("/users", server.usersHandler)
+	mux.HandleFunc("/users/", server.userHandler)
+	mux.HandleFunc("/health", server.healthHandler)
+	mux.HandleFunc("/logs", server.logsHandler)
 
-	// Create HTTP server
+	// Create HTTP server with request logging
 	httpServer := &http.Server{
 		Addr:         ":8080",
-		Handler:      mux,
+		Handler:      isloggerhttp.Middleware(server.logger)(mux),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -90,88 +93,16 @@ func main() {
 	}
 
 	iSlogger.Info("Server stopped gracefully")
-}
-
-// loggingMiddleware logs HTTP requests
-func (s *Server) loggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-
-		// Create request-specific logger
-		requestLogger := s.logger.With(
-			"method", r.Method,
-			"path", r.URL.Path,
-			"remote_addr", r.RemoteAddr,
-			"user_agent", r.UserAgent(),
-			"request_id", generateRequestID(),
-		)
-
-		requestLogger.Info("Request started")
-
-		// Custom response writer to capture status code
-		wrapper := &responseWriter{
-			ResponseWriter: w,
-			statusCode:     200,
-		}
-
-		// Call next handler
-		next(wrapper, r.WithContext(context.WithValue(r.Context(), "logger", requestLogger)))
-
-		// Log request completion
-		duration := time.Since(start)
-		requestLogger.Info("Request completed",
-			"status", wrapper.statusCode,
-			"duration_ms", duration.Milliseconds(),
-			"duration", duration.String(),
-		)
-
-		// Log slow requests as warnings
-		if duration > 1*time.Second {
-			requestLogger.Warn("Slow request detected",
-				"threshold", "1s",
-				"actual_duration", duration.String(),
-			)
-		}
 
-		// Log errors
-		if wrapper.statusCode >= 400 {
-			if wrapper.statusCode >= 500 {
-				requestLogger.Error("Server error",
-					"status", wrapper.statusCode,
-					"category", "server_error",
-				)
-			} else {
-				requestLogger.Warn("Client error",
-					"status", wrapper.statusCode,
-					"category", "client_error",
-				)
-			}
-		}
+	// Write out every pending record before exiting
+	if err := iSlogger.Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Logger shutdown: %v\n", err)
 	}
 }
 
-// responseWriter wraps http.ResponseWriter to capture status code
-type responseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
-
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
-	rw.ResponseWriter.WriteHeader(code)
-}
-
-// getLogger extracts logger from request context
-func getLogger(r *http.Request) *iSlogger.Logger {
-	if logger, ok := r.Context().Value("logger").(*iSlogger.Logger); ok {
-		return logger
-	}
-	return iSlogger.GetGlobalLogger()
-}
-
 // homeHandler handles the root endpoint
 func (s *Server) homeHandler(w http.ResponseWriter, r *http.Request) {
-	logger := getLogger(r)
+	logger := isloggerhttp.FromContext(r.Context())
 
 	logger.Debug("Serving home page")
 
@@ -186,7 +117,7 @@ func (s *Server) homeHandler(w http.ResponseWriter, r *http.Request) {
 
 // usersHandler handles /users endpoint
 func (s *Server) usersHandler(w http.ResponseWriter, r *http.Request) {
-	logger := getLogger(r)
+	logger := isloggerhttp.FromContext(r.Context())
 
 	switch r.Method {
 	case http.MethodGet:
@@ -232,7 +163,7 @@ func (s *Server) usersHandler(w http.ResponseWriter, r *http.Request) {
 
 // userHandler handles /users/{id} endpoint
 func (s *Server) userHandler(w http.ResponseWriter, r *http.Request) {
-	logger := getLogger(r)
+	logger := isloggerhttp.FromContext(r.Context())
 
 	// Extract user ID from path
 	idStr := r.URL.Path[len("/users/"):]
@@ -266,7 +197,7 @@ func (s *Server) userHandler(w http.ResponseWriter, r *http.Request) {
 
 // healthHandler handles health check endpoint
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
-	logger := getLogger(r)
+	logger := isloggerhttp.FromContext(r.Context())
 
 	logger.Debug("Health check requested")
 
@@ -280,9 +211,16 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(health)
 }
 
-// generateRequestID creates a simple request ID
-func generateRequestID() string {
-	return fmt.Sprintf("req-%d", time.Now().UnixNano())
+// logsHandler exposes the most recent log records kept in memory
+func (s *Server) logsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := isloggerhttp.FromContext(r.Context())
+
+	logger.Debug("Recent logs requested")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"records": s.logger.Tail(),
+	})
 }
 
 var startTime = time.Now()
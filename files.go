@@ -0,0 +1,240 @@
+package iSlogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// logFile is an open log file together with its write buffer
+type logFile struct {
+	path   string
+	file   *os.File
+	buffer *bufferedWriter
+}
+
+// openLogFile opens (or creates) a log file inside baseDir for appending and
+// tracks it with the logger's open files
+func (l *Logger) openLogFile(baseDir, name string) (*logFile, error) {
+	lf, err := l.newLogFile(baseDir, name)
+	if err != nil {
+		return nil, err
+	}
+	l.files = append(l.files, lf)
+	return lf, nil
+}
+
+// newLogFile opens (or creates) a log file inside baseDir for appending
+func (l *Logger) newLogFile(baseDir, name string) (*logFile, error) {
+	path := filepath.Join(baseDir, name)
+	if rel, err := filepath.Rel(baseDir, path); err != nil || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("invalid log file path: %s", path)
+	}
+
+	file, err := l.createLogFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file %s: %w", name, err)
+	}
+
+	var writer io.Writer = file
+	if l.config.WriteErrorHandler != nil || l.config.StderrFallback {
+		ew := &errorReportingWriter{writer: file, onError: l.config.WriteErrorHandler}
+		if l.config.StderrFallback {
+			ew.fallback = os.Stderr
+		}
+		writer = ew
+	}
+
+	buffer := newBufferedWriter(writer, l.config.BufferSize, l.config.FlushInterval, l.config.FlushOnLevel)
+	if l.config.MinFlushBytes > 0 {
+		maxAge := l.config.MaxFlushAge
+		if maxAge <= 0 {
+			maxAge = 10 * l.config.FlushInterval
+		}
+		buffer.setMinFlush(l.config.MinFlushBytes, maxAge)
+	}
+	if l.config.OnFlush != nil {
+		buffer.setFlushHook(l.config.OnFlush)
+	}
+
+	return &logFile{
+		path:   path,
+		file:   file,
+		buffer: buffer,
+	}, nil
+}
+
+// fileHandler creates the handler writing records to a log file in the file format
+func (c Config) fileHandler(lf *logFile, opts *slog.HandlerOptions) slog.Handler {
+	return &logFileHandler{handler: c.formatHandler(lf, c.fileFormat(), opts), file: lf}
+}
+
+// logFileHandler writes records to a log file and flushes its buffer after records
+// at FlushOnLevel or above. The decision uses the record's level, so it holds for
+// every format and for renamed levels.
+type logFileHandler struct {
+	handler slog.Handler
+	file    *logFile
+}
+
+// Enabled reports whether the wrapped handler is enabled for the level
+func (h *logFileHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle writes the record, flushing the buffer if its level requires it
+func (h *logFileHandler) Handle(ctx context.Context, record slog.Record) error {
+	if err := h.handler.Handle(ctx, record); err != nil {
+		return err
+	}
+	return h.file.buffer.flushForLevel(record.Level)
+}
+
+// WithAttrs creates a new handler with additional attributes
+func (h *logFileHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logFileHandler{handler: h.handler.WithAttrs(attrs), file: h.file}
+}
+
+// WithGroup creates a new handler with a group
+func (h *logFileHandler) WithGroup(name string) slog.Handler {
+	return &logFileHandler{handler: h.handler.WithGroup(name), file: h.file}
+}
+
+// createLogFile opens the file for appending, writing the configured header
+// only if this call created it. An explicit FileMode is applied to the file
+// whether or not it already existed, and regardless of the umask.
+func (l *Logger) createLogFile(path string) (*os.File, error) {
+	file, created, err := l.openFileMode(path)
+	if err != nil {
+		return nil, err
+	}
+	if l.config.FileMode != 0 {
+		if err := file.Chmod(l.config.FileMode); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	if l.config.FileHeader == nil || !created {
+		return file, nil
+	}
+
+	header := l.config.FileHeader()
+	if !strings.HasSuffix(header, "\n") {
+		header += "\n"
+	}
+	if _, err := file.WriteString(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// openFileMode opens the file for appending. When a header is configured it also
+// reports whether this call created the file; otherwise created is always false.
+func (l *Logger) openFileMode(path string) (file *os.File, created bool, err error) {
+	mode := l.config.fileMode()
+	if l.config.FileHeader == nil {
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, mode)
+		return file, false, err
+	}
+
+	file, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, mode)
+	if errors.Is(err, fs.ErrExist) {
+		file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, mode)
+		return file, false, err
+	}
+	return file, err == nil, err
+}
+
+// errorReportingWriter reports failed writes, which slog handlers otherwise discard,
+// and optionally re-emits the unwritten bytes to a fallback writer
+type errorReportingWriter struct {
+	writer   io.Writer
+	onError  func(error)
+	fallback io.Writer
+}
+
+// Write writes to the underlying writer. On failure it reports the error and,
+// if the fallback accepts the rest of p, the write counts as successful.
+func (w *errorReportingWriter) Write(p []byte) (n int, err error) {
+	n, err = w.writer.Write(p)
+	if err == nil {
+		return n, nil
+	}
+
+	if w.onError != nil {
+		w.onError(err)
+	}
+	if w.fallback != nil {
+		// Fallback errors are not reported again to avoid loops
+		if _, fallbackErr := w.fallback.Write(p[n:]); fallbackErr == nil {
+			return len(p), nil
+		}
+	}
+	return n, err
+}
+
+// Write writes to the file through its buffer
+func (lf *logFile) Write(p []byte) (n int, err error) {
+	return lf.buffer.Write(p)
+}
+
+// Flush flushes the buffer to the file
+func (lf *logFile) Flush() error {
+	return lf.buffer.Flush()
+}
+
+// Sync flushes the buffer and commits the file contents to stable storage
+func (lf *logFile) Sync() error {
+	if err := lf.buffer.Flush(); err != nil {
+		return err
+	}
+	return lf.file.Sync()
+}
+
+// Close flushes the buffer and closes the file
+func (lf *logFile) Close() error {
+	bufErr := lf.buffer.Close()
+	fileErr := lf.file.Close()
+	if bufErr != nil {
+		return bufErr
+	}
+	return fileErr
+}
+
+// linkCurrentFilesLocked points an undated symlink at each open file, e.g. app.log
+// at app_2024-01-15.log. Links are swapped atomically, regular files with the link
+// name are left alone and platforms without symlink support are skipped.
+func (l *Logger) linkCurrentFilesLocked(date string) {
+	for _, lf := range l.files {
+		name := filepath.Base(lf.path)
+		link := filepath.Join(filepath.Dir(lf.path), strings.Replace(name, "_"+date, "", 1))
+
+		if info, err := os.Lstat(link); err == nil && info.Mode()&fs.ModeSymlink == 0 {
+			continue
+		}
+
+		tmp := link + ".tmp"
+		os.Remove(tmp)
+		if err := os.Symlink(name, tmp); err != nil {
+			continue
+		}
+		if err := os.Rename(tmp, link); err != nil {
+			os.Remove(tmp)
+		}
+	}
+}
+
+// logFileName returns the dated file name for the given kind ("" for the main file)
+func (c Config) logFileName(kind, date string) string {
+	if kind == "" {
+		return fmt.Sprintf("%s_%s.log", c.AppName, date)
+	}
+	return fmt.Sprintf("%s_%s_%s.log", c.AppName, kind, date)
+}
@@ -0,0 +1,509 @@
+package iSlogger
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPerLevelFiles(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-per-level").
+		WithLogDir("test-logs-per-level").
+		WithLogLevel(slog.LevelDebug).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithPerLevelFiles(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-per-level")
+
+	logger.Debug("debug record")
+	logger.Info("info record")
+	logger.Warn("warn record")
+	logger.Error("error record")
+
+	today := time.Now().Format("2006-01-02")
+	expected := map[string]string{
+		"debug": "debug record",
+		"info":  "info record",
+		"warn":  "warn record",
+		"error": "error record",
+	}
+
+	for kind, msg := range expected {
+		path := filepath.Join("test-logs-per-level", "test-per-level_"+kind+"_"+today+".log")
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s file: %v", kind, err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		if len(lines) != 1 || !strings.Contains(lines[0], msg) {
+			t.Errorf("Expected %s file to contain only %q, got: %s", kind, msg, content)
+		}
+	}
+
+	files, err := logger.GetLogFiles()
+	if err != nil {
+		t.Fatalf("Failed to get log files: %v", err)
+	}
+	if len(files) != 4 {
+		t.Errorf("Expected 4 log files, got %v", files)
+	}
+}
+
+func TestCombinedFile(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig().
+		WithAppName("test-combined").
+		WithLogDir(dir).
+		WithLogLevel(slog.LevelDebug).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithSeparateErrorFile(false)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Debug("debug record")
+	logger.Info("info record")
+	logger.Warn("warn record")
+	logger.Error("error record")
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read log dir: %v", err)
+	}
+	today := time.Now().Format("2006-01-02")
+	if len(entries) != 1 || entries[0].Name() != "test-combined_"+today+".log" {
+		t.Fatalf("Expected only the combined file, got %v", entries)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatalf("Failed to read combined file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected each record exactly once, got: %s", content)
+	}
+	for i, msg := range []string{"debug record", "info record", "warn record", "error record"} {
+		if !strings.Contains(lines[i], msg) {
+			t.Errorf("Expected line %d to contain %q, got: %s", i+1, msg, lines[i])
+		}
+	}
+
+	if infoPath, errorPath := logger.GetCurrentLogPaths(); infoPath != errorPath {
+		t.Errorf("Expected both paths to point to the combined file, got %s and %s", infoPath, errorPath)
+	}
+}
+
+func TestTenantFiles(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig().
+		WithAppName("test-tenant").
+		WithLogDir(dir).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithTenantField("tenant").
+		WithMaxOpenFiles(1)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	acme := logger.With("tenant", "acme")
+	acme.Info("acme info")
+	logger.Info("globex info", "tenant", "globex")
+	acme.Error("acme error", "user", "alice") // Reopens the file closed for globex
+	logger.Info("no tenant")
+	logger.Info("sneaky", "tenant", "error")
+
+	today := time.Now().Format("2006-01-02")
+	read := func(name string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(dir, "test-tenant_"+name+today+".log"))
+		if err != nil {
+			t.Fatalf("Failed to read %s file: %v", name, err)
+		}
+		return string(content)
+	}
+
+	acmeContent := read("acme_")
+	if !strings.Contains(acmeContent, "acme info") || !strings.Contains(acmeContent, `msg="acme error" tenant=acme user=alice`) {
+		t.Errorf("Expected both acme records in the acme file, got: %s", acmeContent)
+	}
+	if globex := read("globex_"); !strings.Contains(globex, "globex info") || strings.Contains(globex, "acme") {
+		t.Errorf("Expected only the globex record in the globex file, got: %s", globex)
+	}
+	if sneaky := read("_error_"); !strings.Contains(sneaky, "sneaky") {
+		t.Errorf("Expected a tenant named like a regular file to get its own file, got: %s", sneaky)
+	}
+
+	info, errorContent := read(""), read("error_")
+	if !strings.Contains(info, "no tenant") || strings.Contains(info, "tenant=") {
+		t.Errorf("Expected only the record without a tenant in the info file, got: %s", info)
+	}
+	if errorContent != "" {
+		t.Errorf("Expected tenant errors to stay out of the error file, got: %s", errorContent)
+	}
+}
+
+func TestMaxOpenFiles(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig().
+		WithAppName("test-max-open").
+		WithLogDir(dir).
+		WithConsoleOutput(false).
+		WithTenantField("tenant").
+		WithMaxOpenFiles(2)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	const tenants, rounds = 10, 3
+	var wg sync.WaitGroup
+	for i := range tenants {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := range rounds {
+				logger.Info("record", "tenant", fmt.Sprintf("t%d", i), "round", round)
+			}
+		}()
+	}
+	wg.Wait()
+
+	logger.mu.RLock()
+	open := len(logger.tenants.paths())
+	logger.mu.RUnlock()
+	if open > 2 {
+		t.Errorf("Expected at most 2 open tenant files, got %d", open)
+	}
+
+	// Evicted files were flushed on close, the open ones are flushed here
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	today := time.Now().Format("2006-01-02")
+	for i := range tenants {
+		content, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("test-max-open_t%d_%s.log", i, today)))
+		if err != nil {
+			t.Fatalf("Failed to read tenant file: %v", err)
+		}
+		if lines := strings.Count(string(content), "\n"); lines != rounds {
+			t.Errorf("Expected %d records for tenant t%d, got: %s", rounds, i, content)
+		}
+	}
+}
+
+func TestPerLevelFiles_Duplicate(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-per-level-dup").
+		WithLogDir("test-logs-per-level-dup").
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithPerLevelFiles(true).
+		WithPerLevelDuplicate(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-per-level-dup")
+
+	logger.Warn("warn record")
+	logger.Error("error record")
+
+	today := time.Now().Format("2006-01-02")
+	warnContent, err := os.ReadFile(filepath.Join("test-logs-per-level-dup", "test-per-level-dup_warn_"+today+".log"))
+	if err != nil {
+		t.Fatalf("Failed to read warn file: %v", err)
+	}
+	if !strings.Contains(string(warnContent), "warn record") {
+		t.Errorf("Expected warn file to contain warn record, got: %s", warnContent)
+	}
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	errorContent, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if strings.Count(string(errorContent), "warn record") != 1 {
+		t.Errorf("Expected warn record duplicated once into error file, got: %s", errorContent)
+	}
+	if strings.Count(string(errorContent), "error record") != 1 {
+		t.Errorf("Expected error record written once, got: %s", errorContent)
+	}
+}
+
+func TestWriteErrorHandler(t *testing.T) {
+	var reported []error
+	config := DefaultConfig().
+		WithAppName("test-write-error").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithWriteErrorHandler(func(err error) {
+			reported = append(reported, err)
+		})
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("Written")
+	if len(reported) != 0 {
+		t.Fatalf("Expected no write errors, got %v", reported)
+	}
+
+	// Simulate a failing file by closing it behind the logger's back
+	for _, lf := range logger.files {
+		lf.file.Close()
+	}
+
+	logger.Info("Lost")
+	if len(reported) != 1 || !errors.Is(reported[0], os.ErrClosed) {
+		t.Errorf("Expected one closed-file error, got %v", reported)
+	}
+}
+
+func TestStderrFallback(t *testing.T) {
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatalf("Failed to create stderr file: %v", err)
+	}
+	defer stderr.Close()
+
+	origStderr := os.Stderr
+	os.Stderr = stderr
+	defer func() { os.Stderr = origStderr }()
+
+	config := DefaultConfig().
+		WithAppName("test-stderr-fallback").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithStderrFallback(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	// Simulate a failing file by closing it behind the logger's back
+	for _, lf := range logger.files {
+		lf.file.Close()
+	}
+
+	logger.Error("Rescued record")
+
+	content, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatalf("Failed to read captured stderr: %v", err)
+	}
+	if got := strings.Count(string(content), "Rescued record"); got != 1 {
+		t.Errorf("Expected the record once on stderr, got %d: %s", got, content)
+	}
+}
+
+func TestErrorReportingWriter_FallbackFails(t *testing.T) {
+	var reported int
+	w := &errorReportingWriter{
+		writer:   failingWriter{},
+		onError:  func(error) { reported++ },
+		fallback: failingWriter{},
+	}
+
+	if _, err := w.Write([]byte("record\n")); err == nil {
+		t.Error("Expected an error when the fallback fails too")
+	}
+	if reported != 1 {
+		t.Errorf("Expected the primary error to be reported once, got %d", reported)
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestFileHeader(t *testing.T) {
+	header := `{"app":"test-header","schema":1}`
+	config := DefaultConfig().
+		WithAppName("test-header").
+		WithLogDir("test-logs-header").
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithFileHeader(func() string { return header })
+	defer os.RemoveAll("test-logs-header")
+
+	for i := range 2 {
+		logger, err := New(config)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		logger.Info("record", "run", i)
+		if err := logger.RotateNow(); err != nil {
+			t.Fatalf("Failed to rotate: %v", err)
+		}
+		logger.Info("after rotate", "run", i)
+		logger.Close()
+	}
+
+	today := time.Now().Format("2006-01-02")
+	for _, name := range []string{config.logFileName("", today), config.logFileName("error", today)} {
+		content, err := os.ReadFile(filepath.Join("test-logs-header", name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		lines := strings.Split(string(content), "\n")
+		if lines[0] != header {
+			t.Errorf("Expected %s to start with the header, got: %s", name, content)
+		}
+		if count := strings.Count(string(content), header); count != 1 {
+			t.Errorf("Expected the header once in %s, got %d times", name, count)
+		}
+	}
+}
+
+func TestFilePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permission bits are not supported on Windows")
+	}
+
+	dir := filepath.Join(t.TempDir(), "logs")
+	today := time.Now().Format("2006-01-02")
+
+	// The info file already exists with a looser mode
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create log dir: %v", err)
+	}
+	existing := filepath.Join(dir, "test-perms_"+today+".log")
+	if err := os.WriteFile(existing, nil, 0o644); err != nil {
+		t.Fatalf("Failed to create existing file: %v", err)
+	}
+
+	config := DefaultConfig().
+		WithAppName("test-perms").
+		WithLogDir(dir).
+		WithConsoleOutput(false).
+		WithFilePermissions(0o640).
+		WithDirPermissions(0o750)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	infoPath, errorPath := logger.GetCurrentLogPaths()
+	for _, path := range []string{infoPath, errorPath} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", path, err)
+		}
+		if got := info.Mode().Perm(); got != 0o640 {
+			t.Errorf("Expected %s to have mode 0640, got %o", filepath.Base(path), got)
+		}
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Failed to stat log dir: %v", err)
+	}
+	if got := info.Mode().Perm(); got != 0o750 {
+		t.Errorf("Expected log dir to have mode 0750, got %o", got)
+	}
+}
+
+func TestCurrentSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Symlinks need extra privileges on Windows")
+	}
+
+	var now atomic.Int64
+	now.Store(time.Date(2024, 3, 9, 23, 59, 59, 0, time.Local).UnixNano())
+
+	dir := t.TempDir()
+	config := DefaultConfig().
+		WithAppName("test-link").
+		WithLogDir(dir).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithCurrentSymlink(true).
+		WithClock(func() time.Time { return time.Unix(0, now.Load()) })
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	assertLinks := func(date string) {
+		t.Helper()
+		for link, target := range map[string]string{
+			"test-link.log":       "test-link_" + date + ".log",
+			"test-link_error.log": "test-link_error_" + date + ".log",
+		} {
+			got, err := os.Readlink(filepath.Join(dir, link))
+			if err != nil {
+				t.Fatalf("Failed to read symlink %s: %v", link, err)
+			}
+			if got != target {
+				t.Errorf("Expected %s to point at %s, got %s", link, target, got)
+			}
+		}
+	}
+
+	assertLinks("2024-03-09")
+
+	now.Add(int64(2 * time.Second))
+	logger.Info("After midnight")
+	assertLinks("2024-03-10")
+
+	content, err := os.ReadFile(filepath.Join(dir, "test-link.log"))
+	if err != nil {
+		t.Fatalf("Failed to read through symlink: %v", err)
+	}
+	if !strings.Contains(string(content), "After midnight") {
+		t.Errorf("Expected the symlink to resolve to the new file, got: %s", content)
+	}
+
+	files, err := logger.GetLogFiles()
+	if err != nil {
+		t.Fatalf("GetLogFiles failed: %v", err)
+	}
+	for _, file := range files {
+		if file == "test-link.log" || file == "test-link_error.log" {
+			t.Errorf("Expected symlinks to be excluded from GetLogFiles, got %v", files)
+		}
+	}
+}
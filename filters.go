@@ -3,11 +3,16 @@ package iSlogger
 import (
 	"log/slog"
 	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// LogCondition defines a function that determines whether a log entry should be written
+// LogCondition defines a function that determines whether a log entry should be written.
+// The attrs slice is reused after the call and must not be retained.
 type LogCondition func(level slog.Level, msg string, attrs []slog.Attr) bool
 
 // FieldFilter defines a function that filters/modifies field values
@@ -22,8 +27,193 @@ type FilterConfig struct {
 	FieldFilters map[string]FieldFilter
 	RegexFilters []RegexFilter
 
-	// Rate limiting
-	RateLimits map[slog.Level]RateLimit
+	// Rate limiting (a level uses either a window or a token bucket)
+	RateLimits   map[slog.Level]RateLimit
+	TokenBuckets map[slog.Level]TokenBucket
+
+	// Global rate limiting across all levels, checked before per-level limits
+	GlobalRateLimit    RateLimit // Zero MaxCount disables it
+	GlobalBypassErrors bool      // Let ERROR records through even when the global limit is hit
+
+	// Per-message rate limiting, every distinct message gets its own budget
+	MessageRateLimit RateLimit // Zero MaxCount disables it
+
+	// Adaptive sampling of DEBUG and INFO records during bursts
+	SamplingTarget int // Records per second to hold near (0 = disabled)
+
+	// Level overrides lower the threshold for matching records
+	LevelOverrides []LevelOverride
+
+	// Caller package filtering, by import path; a path also covers its subpackages
+	AllowPackages []string // Keep only records logged from these packages (empty = all)
+	DenyPackages  []string // Drop records logged from these packages, even if allowed
+
+	// Size limits
+	MaxValueLength int // Maximum length of string attribute values (0 = unlimited)
+	MaxAttrs       int // Maximum number of attributes per record, excluding With-bound ones (0 = unlimited)
+
+	// Drop attributes with nil, empty string or empty group values
+	DropEmptyAttrs bool
+
+	// Replace invalid UTF-8 sequences in string values with U+FFFD
+	SanitizeUTF8 bool
+
+	// Order of a record's own attributes (zero value = as logged)
+	AttrSort AttrSort
+}
+
+// conditionsPass reports whether a record passes every condition (AND logic)
+func (fc FilterConfig) conditionsPass(level slog.Level, msg string, attrs []slog.Attr) bool {
+	for _, condition := range fc.Conditions {
+		if !condition(level, msg, attrs) {
+			return false
+		}
+	}
+	return true
+}
+
+// recordPasses reports whether a record's own attributes pass every condition
+func (fc FilterConfig) recordPasses(record slog.Record) bool {
+	if len(fc.Conditions) == 0 {
+		return true
+	}
+
+	attrsBuf := getAttrBuffer()
+	defer putAttrBuffer(attrsBuf)
+	attrs := *attrsBuf
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	*attrsBuf = attrs
+	return fc.conditionsPass(record.Level, record.Message, attrs)
+}
+
+// hasFilters reports whether any attribute filtering is configured.
+// Rate limits are enforced by the logger's rateLimiter instead.
+func (fc FilterConfig) hasFilters() bool {
+	return len(fc.Conditions) > 0 ||
+		len(fc.FieldFilters) > 0 ||
+		len(fc.RegexFilters) > 0 ||
+		fc.MaxValueLength > 0 ||
+		fc.MaxAttrs > 0 ||
+		fc.DropEmptyAttrs ||
+		fc.SanitizeUTF8 ||
+		fc.AttrSort.enabled
+}
+
+// AttrSort orders the attributes of each record by key
+type AttrSort struct {
+	enabled  bool
+	priority []string // Keys written first, in this order
+}
+
+// SortAlpha orders attributes alphabetically by key
+var SortAlpha = AttrSort{enabled: true}
+
+// SortByPriority writes the given keys first, in the given order, and the rest alphabetically
+func SortByPriority(keys []string) AttrSort {
+	return AttrSort{enabled: true, priority: slices.Clone(keys)}
+}
+
+// sort orders the attributes in place. Nested group members keep their order.
+func (s AttrSort) sort(attrs []slog.Attr) {
+	rank := func(key string) int {
+		if i := slices.Index(s.priority, key); i >= 0 {
+			return i
+		}
+		return len(s.priority)
+	}
+	slices.SortStableFunc(attrs, func(a, b slog.Attr) int {
+		if c := rank(a.Key) - rank(b.Key); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+}
+
+// LevelOverride logs records matching Condition from Level upwards,
+// even when Level is below the logger's level
+type LevelOverride struct {
+	Condition LogCondition
+	Level     slog.Level
+}
+
+// overrideFloor returns the lowest override level, or false if there are no overrides
+func (fc FilterConfig) overrideFloor() (slog.Level, bool) {
+	if len(fc.LevelOverrides) == 0 {
+		return 0, false
+	}
+	floor := fc.LevelOverrides[0].Level
+	for _, override := range fc.LevelOverrides[1:] {
+		floor = min(floor, override.Level)
+	}
+	return floor, true
+}
+
+// levelOverridden reports whether a level override lets the record through
+func (fc FilterConfig) levelOverridden(record slog.Record) bool {
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+
+	for _, override := range fc.LevelOverrides {
+		if record.Level >= override.Level && override.Condition(record.Level, record.Message, attrs) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPackageFilter reports whether records are filtered by caller package
+func (fc FilterConfig) hasPackageFilter() bool {
+	return len(fc.AllowPackages) > 0 || len(fc.DenyPackages) > 0
+}
+
+// packageAllowed reports whether the package of the function at pc passes the
+// package filter. Records without a caller, such as internal warnings, always pass.
+func (fc FilterConfig) packageAllowed(pc uintptr) bool {
+	if pc == 0 {
+		return true
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkg := funcPackage(frame.Function)
+
+	if slices.ContainsFunc(fc.DenyPackages, func(p string) bool { return inPackage(pkg, p) }) {
+		return false
+	}
+	return len(fc.AllowPackages) == 0 ||
+		slices.ContainsFunc(fc.AllowPackages, func(p string) bool { return inPackage(pkg, p) })
+}
+
+// funcPackage returns the import path of a fully qualified function name,
+// e.g. "example.com/app/db" for "example.com/app/db.(*Conn).Query". Dots in
+// the last path element are escaped as %2e in symbol names.
+func funcPackage(function string) string {
+	lastSlash := strings.LastIndexByte(function, '/')
+	if dot := strings.IndexByte(function[lastSlash+1:], '.'); dot >= 0 {
+		function = function[:lastSlash+1+dot]
+	}
+	return strings.ReplaceAll(function, "%2e", ".")
+}
+
+// inPackage reports whether pkg is the package path or one of its subpackages
+func inPackage(pkg, path string) bool {
+	return pkg == path || strings.HasPrefix(pkg, path+"/")
+}
+
+// floorLeveler reports the lower of the logger's level and the override floor,
+// so handlers stay enabled for records a level override may let through
+type floorLeveler struct {
+	level *slog.LevelVar
+	floor slog.Level
+}
+
+// Level returns the effective handler level
+func (fl floorLeveler) Level() slog.Level {
+	return min(fl.level.Level(), fl.floor)
 }
 
 // RegexFilter defines a regex-based field filter
@@ -34,10 +224,14 @@ type RegexFilter struct {
 
 // RateLimit defines rate limiting configuration
 type RateLimit struct {
-	MaxCount  int           // Maximum number of logs per period
-	Period    time.Duration // Time period for rate limiting
-	counter   int64         // Internal counter
-	lastReset time.Time     // Internal last reset time
+	MaxCount int           // Maximum number of logs per period
+	Period   time.Duration // Time period for rate limiting
+}
+
+// TokenBucket defines token bucket rate limiting configuration
+type TokenBucket struct {
+	Rate  float64 // Tokens added per second
+	Burst int     // Maximum number of tokens
 }
 
 // DefaultFilterConfig returns default filter configuration
@@ -47,6 +241,7 @@ func DefaultFilterConfig() FilterConfig {
 		FieldFilters: make(map[string]FieldFilter),
 		RegexFilters: []RegexFilter{},
 		RateLimits:   make(map[slog.Level]RateLimit),
+		TokenBuckets: make(map[slog.Level]TokenBucket),
 	}
 }
 
@@ -59,10 +254,40 @@ func MaskFieldFilter(mask string) FieldFilter {
 	}
 }
 
-// RedactFieldFilter completely removes the field by setting it to empty
+// redacted marks a value whose attribute must be removed from the record.
+// It prints as an empty string wherever it is rendered directly.
+type redacted struct{}
+
+// String returns an empty string
+func (redacted) String() string { return "" }
+
+// RedactFieldFilter completely removes the field
 func RedactFieldFilter() FieldFilter {
 	return func(key string, value slog.Value) slog.Value {
-		return slog.StringValue("")
+		return slog.AnyValue(redacted{})
+	}
+}
+
+// isRedacted reports whether a field filter asked for the attribute to be removed
+func isRedacted(value slog.Value) bool {
+	if value.Kind() != slog.KindAny {
+		return false
+	}
+	_, ok := value.Any().(redacted)
+	return ok
+}
+
+// isEmptyValue reports whether the value is nil, an empty string or an empty group
+func isEmptyValue(value slog.Value) bool {
+	switch value.Kind() {
+	case slog.KindAny:
+		return value.Any() == nil
+	case slog.KindString:
+		return value.String() == ""
+	case slog.KindGroup:
+		return len(value.Group()) == 0
+	default:
+		return false
 	}
 }
 
@@ -102,11 +327,59 @@ func AttributeCondition(key string, expectedValue string) LogCondition {
 	}
 }
 
-// TimeBasedCondition creates a condition based on time of day
+// AttributePresentCondition creates a condition that matches when the attribute key is present
+func AttributePresentCondition(key string) LogCondition {
+	return func(level slog.Level, msg string, attrs []slog.Attr) bool {
+		for _, attr := range attrs {
+			if attr.Key == key {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AttributeAbsentCondition creates a condition that matches when the attribute key is absent
+func AttributeAbsentCondition(key string) LogCondition {
+	present := AttributePresentCondition(key)
+	return func(level slog.Level, msg string, attrs []slog.Attr) bool {
+		return !present(level, msg, attrs)
+	}
+}
+
+// nowFunc is the default time source, replaced in tests. It alone drives the
+// time-based conditions; Config.Clock does not reach them.
+var nowFunc = time.Now
+
+// TimeBasedCondition creates a condition based on time of day. Both hours are inclusive,
+// and a window with endHour before startHour wraps around midnight, e.g. 22 to 6.
+// The hour comes from the real clock, not the logger's Config.Clock.
 func TimeBasedCondition(startHour, endHour int) LogCondition {
+	return hourWindowCondition(startHour, endHour, nowFunc)
+}
+
+// hourWindowCondition matches when the hour reported by now is within the window
+func hourWindowCondition(startHour, endHour int, now func() time.Time) LogCondition {
+	return func(level slog.Level, msg string, attrs []slog.Attr) bool {
+		hour := now().Hour()
+		if startHour <= endHour {
+			return hour >= startHour && hour <= endHour
+		}
+		return hour >= startHour || hour <= endHour
+	}
+}
+
+// WeekdayCondition creates a condition that matches on the given days of the week,
+// read from the real clock like TimeBasedCondition
+func WeekdayCondition(days ...time.Weekday) LogCondition {
+	return weekdayCondition(days, nowFunc)
+}
+
+// weekdayCondition matches when the day reported by now is one of days
+func weekdayCondition(days []time.Weekday, now func() time.Time) LogCondition {
+	days = slices.Clone(days)
 	return func(level slog.Level, msg string, attrs []slog.Attr) bool {
-		hour := time.Now().Hour()
-		return hour >= startHour && hour <= endHour
+		return slices.Contains(days, now().Weekday())
 	}
 }
 
@@ -133,3 +406,35 @@ func AnyCondition(conditions ...LogCondition) LogCondition {
 		return false
 	}
 }
+
+// maxSampledMessages bounds the distinct messages SampleFirstThenEvery counts separately
+const maxSampledMessages = 10000
+
+// SampleFirstThenEvery creates a condition that passes the first occurrences of each
+// message in full, then one in every after them: with (3, 10), occurrences 1-3, 13,
+// 23 and so on pass. An every of 0 or less passes nothing after the first ones.
+// Counts are kept per distinct message for the life of the condition; beyond
+// maxSampledMessages messages, new ones share a single count so formatted
+// messages can't grow the counts without bound.
+func SampleFirstThenEvery(first, every int) LogCondition {
+	var (
+		counts   sync.Map     // Message to *atomic.Int64 occurrence count
+		distinct atomic.Int64 // Messages in counts
+		overflow atomic.Int64 // Shared count of the messages beyond the limit
+	)
+	return func(level slog.Level, msg string, attrs []slog.Attr) bool {
+		count, ok := counts.Load(msg)
+		if !ok {
+			if distinct.Load() >= maxSampledMessages {
+				count = &overflow
+			} else if count, ok = counts.LoadOrStore(msg, new(atomic.Int64)); !ok {
+				distinct.Add(1)
+			}
+		}
+		n := count.(*atomic.Int64).Add(1)
+		if n <= int64(first) {
+			return true
+		}
+		return every > 0 && (n-int64(first))%int64(every) == 0
+	}
+}
@@ -1,8 +1,15 @@
 package iSlogger
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"os"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -47,6 +54,82 @@ func TestFieldRedaction(t *testing.T) {
 	// Test should complete without errors
 }
 
+func TestFieldRedaction_KeepsEmptyValues(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-redact-empty").
+		WithLogDir("test-logs-redact-empty").
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithFieldRedaction("sensitive_data")
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-redact-empty")
+
+	logger.Info("Processing", "note", "", "sensitive_data", "should_not_appear")
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	output := string(content)
+
+	if !strings.Contains(output, `note=""`) {
+		t.Errorf("Expected intentionally empty value to survive, got: %s", output)
+	}
+	if strings.Contains(output, "sensitive_data") {
+		t.Errorf("Expected redacted field to be removed, got: %s", output)
+	}
+	if got := logger.Stats().FieldsRemoved; got != 1 {
+		t.Errorf("Expected 1 field removed, got %d", got)
+	}
+}
+
+func TestFieldMasking_NestedGroups(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-mask-nested").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithFieldMask("password", "***").
+		WithFieldMask("billing.card.number", "****").
+		WithFieldRedaction("token").
+		WithRegexFilter(`\d{3}-\d{4}`, "xxx-xxxx")
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("Signup",
+		slog.Group("user", "id", 1, "password", "secret", "token", "abc", "phone", "555-1234"),
+		slog.Group("billing", slog.Group("card", "number", "4111111111111111")),
+		"number", "42")
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	output := string(content)
+
+	for _, expected := range []string{"user.id=1", "user.password=***", "user.phone=xxx-xxxx", "billing.card.number=****", "number=42"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in output, got: %s", expected, output)
+		}
+	}
+	for _, leaked := range []string{"secret", "token", "555-1234", "4111111111111111"} {
+		if strings.Contains(output, leaked) {
+			t.Errorf("Expected %q to be filtered, got: %s", leaked, output)
+		}
+	}
+}
+
 func TestRegexFilter(t *testing.T) {
 	config := DefaultConfig().
 		WithAppName("test-regex").
@@ -213,6 +296,93 @@ func TestConditionHelpers(t *testing.T) {
 	}
 }
 
+func TestTimeBasedCondition_Overnight(t *testing.T) {
+	var now time.Time
+	clock := func() time.Time { return now }
+	overnight := hourWindowCondition(22, 6, clock)
+
+	tests := []struct {
+		hour     int
+		expected bool
+	}{
+		{21, false},
+		{22, true},
+		{23, true},
+		{0, true},
+		{6, true},
+		{7, false},
+		{12, false},
+	}
+
+	for _, test := range tests {
+		now = time.Date(2024, 3, 9, test.hour, 30, 0, 0, time.Local)
+		if got := overnight(slog.LevelInfo, "test", nil); got != test.expected {
+			t.Errorf("Overnight window at %02d:30 = %v, expected %v", test.hour, got, test.expected)
+		}
+	}
+
+	// Windows that don't wrap keep their inclusive bounds
+	now = time.Date(2024, 3, 9, 17, 59, 0, 0, time.Local)
+	if !hourWindowCondition(9, 17, clock)(slog.LevelInfo, "test", nil) {
+		t.Error("Work hours window should include its end hour")
+	}
+}
+
+func TestWeekdayCondition(t *testing.T) {
+	var now time.Time
+	weekends := weekdayCondition([]time.Weekday{time.Saturday, time.Sunday}, func() time.Time { return now })
+
+	// 2024-03-09 is a Saturday
+	for day := 9; day <= 15; day++ {
+		now = time.Date(2024, 3, day, 12, 0, 0, 0, time.Local)
+		expected := now.Weekday() == time.Saturday || now.Weekday() == time.Sunday
+		if got := weekends(slog.LevelInfo, "test", nil); got != expected {
+			t.Errorf("Weekend condition on %s = %v, expected %v", now.Weekday(), got, expected)
+		}
+	}
+
+	if WeekdayCondition()(slog.LevelInfo, "test", nil) {
+		t.Error("Condition without days should never match")
+	}
+}
+
+func TestAttributePresentCondition(t *testing.T) {
+	attrs := []slog.Attr{
+		slog.String("trace_id", ""),
+		slog.Int("user_id", 123),
+	}
+
+	if !AttributePresentCondition("trace_id")(slog.LevelInfo, "test", attrs) {
+		t.Error("Present condition should match when the key exists, even with an empty value")
+	}
+	if AttributePresentCondition("span_id")(slog.LevelInfo, "test", attrs) {
+		t.Error("Present condition should not match when the key is missing")
+	}
+	if AttributePresentCondition("trace_id")(slog.LevelInfo, "test", nil) {
+		t.Error("Present condition should not match a record without attributes")
+	}
+}
+
+func TestAttributeAbsentCondition(t *testing.T) {
+	attrs := []slog.Attr{slog.String("trace_id", "abc")}
+
+	if AttributeAbsentCondition("trace_id")(slog.LevelInfo, "test", attrs) {
+		t.Error("Absent condition should not match when the key exists")
+	}
+	if !AttributeAbsentCondition("span_id")(slog.LevelInfo, "test", attrs) {
+		t.Error("Absent condition should match when the key is missing")
+	}
+
+	// Composes with the existing combinators
+	errorsWithoutTrace := CombineConditions(LevelCondition(slog.LevelError), AttributeAbsentCondition("trace_id"))
+	if !errorsWithoutTrace(slog.LevelError, "test", nil) {
+		t.Error("Combined condition should match an error without trace_id")
+	}
+	if errorsWithoutTrace(slog.LevelError, "test", attrs) {
+		t.Error("Combined condition should not match an error with trace_id")
+	}
+}
+
 func TestCombineConditions(t *testing.T) {
 	// Test AND logic
 	levelCond := LevelCondition(slog.LevelInfo)
@@ -247,6 +417,58 @@ func TestAnyCondition(t *testing.T) {
 	}
 }
 
+func TestSampleFirstThenEvery(t *testing.T) {
+	condition := SampleFirstThenEvery(3, 5)
+
+	var passed []int
+	for n := 1; n <= 20; n++ {
+		if condition(slog.LevelInfo, "retrying", nil) {
+			passed = append(passed, n)
+		}
+		// Other messages keep their own count
+		if n == 10 && !condition(slog.LevelInfo, "connected", nil) {
+			t.Error("Expected the first occurrence of another message to pass")
+		}
+	}
+	if fmt.Sprint(passed) != "[1 2 3 8 13 18]" {
+		t.Errorf("Expected the first 3 then every 5th occurrence, got %v", passed)
+	}
+
+	// Beyond the limit on distinct messages, new ones share one count
+	bounded := SampleFirstThenEvery(1, 0)
+	for i := range maxSampledMessages {
+		bounded(slog.LevelInfo, fmt.Sprintf("request %d done", i), nil)
+	}
+	if !bounded(slog.LevelInfo, "overflow a", nil) || bounded(slog.LevelInfo, "overflow b", nil) {
+		t.Error("Expected messages beyond the limit to share a single count")
+	}
+	if bounded(slog.LevelInfo, "request 0 done", nil) {
+		t.Error("Expected messages within the limit to keep their own count")
+	}
+
+	// Through a logger each record is counted once, even when it reaches both streams
+	config := DefaultConfig().
+		WithAppName("test-first-every").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithInMemoryTail(10).
+		WithCondition(AnyCondition(LevelCondition(slog.LevelError), SampleFirstThenEvery(1, 2)))
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	for range 5 {
+		logger.Warn("disk almost full")
+	}
+	if got := len(logger.Tail()); got != 3 {
+		t.Errorf("Expected occurrences 1, 3 and 5, got %d records", got)
+	}
+}
+
 func TestMaskFieldFilter(t *testing.T) {
 	filter := MaskFieldFilter("***")
 	result := filter("password", slog.StringValue("secret123"))
@@ -262,3 +484,843 @@ func TestRedactFieldFilter(t *testing.T) {
 		t.Errorf("Expected empty string, got '%s'", result.String())
 	}
 }
+
+func TestDropEmptyAttrs(t *testing.T) {
+	handle := func(config Config) string {
+		var buf bytes.Buffer
+		handler := newFilteredHandler(slog.NewTextHandler(&buf, nil), config.Filters, nil)
+		record := slog.NewRecord(time.Now(), slog.LevelInfo, "test", 0)
+		record.Add("err", nil, "name", "", "empty_group", slog.GroupValue(), "user", "alice", "count", 0)
+		handler.Handle(context.Background(), record)
+		return buf.String()
+	}
+
+	output := handle(DefaultConfig().WithDropEmptyAttrs(true))
+	for _, key := range []string{"err=", "name=", "empty_group"} {
+		if strings.Contains(output, key) {
+			t.Errorf("Expected %s to be dropped, got: %s", key, output)
+		}
+	}
+	if !strings.Contains(output, "user=alice") || !strings.Contains(output, "count=0") {
+		t.Errorf("Expected present values to be kept, got: %s", output)
+	}
+
+	// Without the option, empty values are kept even when other filters are active
+	output = handle(DefaultConfig().WithFieldRedaction("user"))
+	if !strings.Contains(output, "err=<nil>") || !strings.Contains(output, `name=""`) {
+		t.Errorf("Expected empty values to be kept by default, got: %s", output)
+	}
+	if strings.Contains(output, "user=") {
+		t.Errorf("Expected redacted field to be removed, got: %s", output)
+	}
+}
+
+func TestSanitizeUTF8(t *testing.T) {
+	var buf bytes.Buffer
+	config := DefaultConfig().WithSanitizeUTF8(true)
+	handler := newFilteredHandler(slog.NewJSONHandler(&buf, nil), config.Filters, nil)
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "packet", 0)
+	record.Add("payload", "ok\xff\xfe\xfdend", slog.Group("peer", "name", "\xc3("))
+	handler.Handle(context.Background(), record)
+
+	line := bytes.TrimSpace(buf.Bytes())
+	if !json.Valid(line) {
+		t.Fatalf("Expected valid JSON, got: %s", line)
+	}
+	var decoded struct {
+		Payload string
+		Peer    struct{ Name string }
+	}
+	if err := json.Unmarshal(line, &decoded); err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+	// A run of invalid bytes becomes a single replacement character
+	if decoded.Payload != "ok\uFFFDend" || decoded.Peer.Name != "\uFFFD(" {
+		t.Errorf("Expected sanitized values, got %q and %q", decoded.Payload, decoded.Peer.Name)
+	}
+}
+
+func TestMaxValueLength(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-max-value").
+		WithLogDir("test-logs-max-value").
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithMaxValueLength(10)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-max-value")
+
+	logger.Info("Short", "body", "0123456789")
+	logger.Info("Long", "body", strings.Repeat("x", 100))
+	logger.Info("Stringified", "body", []int{1, 2, 3, 4, 5, 6, 7, 8, 9})
+
+	lines := logger.Tail()
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(lines))
+	}
+
+	if !strings.Contains(lines[0], "body=0123456789") || strings.Contains(lines[0], "_truncated") {
+		t.Errorf("Expected value at the limit to be kept intact, got: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `body=xxxxxxxxxx...`) || !strings.Contains(lines[1], "_truncated=true") {
+		t.Errorf("Expected long value to be truncated, got: %s", lines[1])
+	}
+	if !strings.Contains(lines[2], `body="[1 2 3 4 5...`) || !strings.Contains(lines[2], "_truncated=true") {
+		t.Errorf("Expected stringified value to be truncated, got: %s", lines[2])
+	}
+}
+
+// bodyValuer is a LogValuer resolving to a group with a long value
+type bodyValuer struct{}
+
+func (bodyValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("body", strings.Repeat("y", 100)))
+}
+
+func TestMaxValueLength_Groups(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-max-value-groups").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithMaxValueLength(10)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("Grouped", slog.Group("request", "id", 1, "body", strings.Repeat("x", 100)))
+	logger.Info("Valuer", "response", bodyValuer{})
+
+	lines := logger.Tail()
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], "request.body=xxxxxxxxxx... ") || !strings.Contains(lines[0], "_truncated=true") {
+		t.Errorf("Expected the grouped value to be truncated, got: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "response.body=yyyyyyyyyy... ") || !strings.Contains(lines[1], "_truncated=true") {
+		t.Errorf("Expected the value in the resolved group to be truncated, got: %s", lines[1])
+	}
+}
+
+func TestMaxValueLength_AfterMasking(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-max-value-mask").
+		WithLogDir("test-logs-max-value-mask").
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithFieldMask("token", "***").
+		WithMaxValueLength(5)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-max-value-mask")
+
+	logger.Info("Auth", "token", strings.Repeat("secret", 10))
+
+	lines := logger.Tail()
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], "token=***") || strings.Contains(lines[0], "_truncated") {
+		t.Errorf("Expected masked value not to be truncated, got: %s", lines[0])
+	}
+}
+
+// cardNumber is a LogValuer resolving to a string with digits
+type cardNumber struct{}
+
+func (cardNumber) LogValue() slog.Value {
+	return slog.StringValue("card 4111")
+}
+
+func TestFilters_PreserveJSONTypes(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-json-types").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithJSONFormat(true).
+		WithRegexFilter(`\d`, "#").
+		WithMaxValueLength(3).
+		WithDropEmptyAttrs(true).
+		WithFieldMask("pin", "***")
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("Order placed",
+		"count", 42,
+		"big", int64(1234567890),
+		"ratio", 1.5,
+		"ok", true,
+		"zero", 0,
+		"no", false,
+		"elapsed", time.Second,
+		"pin", 1234,
+		"card", cardNumber{},
+		slog.Group("event", "time", 42, "id", 12345),
+	)
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(logger.Tail()[0]), &record); err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+
+	expected := map[string]any{
+		"count":   float64(42),
+		"big":     float64(1234567890),
+		"ratio":   1.5,
+		"ok":      true,
+		"zero":    float64(0),
+		"no":      false,
+		"elapsed": float64(time.Second),
+		"pin":     "***", // Explicitly targeted by a filter
+		"card":    "car...",
+	}
+	for key, want := range expected {
+		if got := record[key]; got != want {
+			t.Errorf("Expected %s to be %v (%T), got %v (%T)", key, want, want, got, got)
+		}
+	}
+
+	event, _ := record["event"].(map[string]any)
+	if event["time"] != float64(42) || event["id"] != float64(12345) {
+		t.Errorf("Expected grouped numbers to stay numbers, got %v", record["event"])
+	}
+}
+
+func TestRegexFilter_LogValuer(t *testing.T) {
+	var buf bytes.Buffer
+	config := DefaultConfig().WithRegexFilter(`\d`, "#")
+	handler := newFilteredHandler(slog.NewTextHandler(&buf, nil), config.Filters, nil)
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "test", 0)
+	record.Add("card", cardNumber{})
+	handler.Handle(context.Background(), record)
+
+	if !strings.Contains(buf.String(), `card="card ####"`) {
+		t.Errorf("Expected the resolved value to be filtered, got: %s", buf.String())
+	}
+}
+
+func TestAttrSort(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     AttrSort
+		expected string
+	}{
+		{"as logged", AttrSort{}, "zone=eu user=alice request_id=r1 amount=42 trace_id=t1"},
+		{"alphabetical", SortAlpha, "amount=42 request_id=r1 trace_id=t1 user=alice zone=eu"},
+		{"priority", SortByPriority([]string{"request_id", "trace_id"}), "request_id=r1 trace_id=t1 amount=42 user=alice zone=eu"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := DefaultConfig().
+				WithAppName("test-attr-sort").
+				WithLogDir(t.TempDir()).
+				WithConsoleOutput(false).
+				WithInMemoryTail(1).
+				WithAttrSort(test.sort)
+
+			logger, err := New(config)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer logger.Close()
+
+			logger.With("service", "api").Info("Order", "zone", "eu", "user", "alice", "request_id", "r1", "amount", 42, "trace_id", "t1")
+
+			// Bound attributes stay in front of the sorted ones
+			if _, got, _ := strings.Cut(logger.Tail()[0], "msg=Order service=api "); got != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestAttrSort_BoundAttrs(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-attr-sort-bound").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithInMemoryTail(1).
+		WithAttrSort(SortByPriority([]string{"request_id"}))
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	// Bound like the HTTP middleware does, with request_id last
+	requestLogger := logger.With("method", "GET", "path", "/orders", "remote_addr", "10.0.0.1", "request_id", "r1")
+	requestLogger.Info("Request completed", "status", 200, "duration_ms", 5)
+
+	expected := "request_id=r1 method=GET path=/orders remote_addr=10.0.0.1 duration_ms=5 status=200"
+	if _, got, _ := strings.Cut(logger.Tail()[0], "msg=\"Request completed\" "); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestMaxAttrs(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-max-attrs").
+		WithLogDir("test-logs-max-attrs").
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithMaxAttrs(10)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-max-attrs")
+
+	args := make([]any, 0, 100)
+	for i := range 50 {
+		args = append(args, fmt.Sprintf("key%d", i), i)
+	}
+	logger.Info("Many attrs", args...)
+	logger.With("service", "api", "region", "eu").Info("Bound attrs", args[:20]...)
+
+	lines := logger.Tail()
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(lines))
+	}
+
+	if !strings.Contains(lines[0], "key9=9") || strings.Contains(lines[0], "key10=") {
+		t.Errorf("Expected only the first 10 attributes, got: %s", lines[0])
+	}
+	if !strings.Contains(lines[0], "attrs_truncated=40") {
+		t.Errorf("Expected attrs_truncated=40, got: %s", lines[0])
+	}
+
+	// With-bound attributes are exempt from the limit
+	if !strings.Contains(lines[1], "service=api region=eu") || !strings.Contains(lines[1], "key9=9") {
+		t.Errorf("Expected With-bound attributes plus 10 record attributes, got: %s", lines[1])
+	}
+	if strings.Contains(lines[1], "attrs_truncated") {
+		t.Errorf("Expected no truncation when record attributes fit the limit, got: %s", lines[1])
+	}
+}
+
+func TestRateLimit_ExactCount(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-rate-exact").
+		WithLogDir("test-logs-rate-exact").
+		WithConsoleOutput(false).
+		WithInMemoryTail(1000).
+		WithRateLimit(slog.LevelInfo, 50, time.Hour).
+		WithRateLimit(slog.LevelWarn, 10, time.Hour)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-rate-exact")
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				logger.Info("Info message")
+				logger.Warn("Warn message")
+			}
+		}()
+	}
+	wg.Wait()
+
+	var infos, warns int
+	for _, line := range logger.Tail() {
+		switch {
+		case strings.Contains(line, "level=INFO"):
+			infos++
+		case strings.Contains(line, "level=WARN"):
+			warns++
+		}
+	}
+	if infos != 50 {
+		t.Errorf("Expected exactly 50 INFO records, got %d", infos)
+	}
+	if warns != 10 {
+		t.Errorf("Expected exactly 10 WARN records, got %d", warns)
+	}
+
+	// WARN records go to both streams but must only be counted once
+	logger.Flush()
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if got := strings.Count(string(content), "Warn message"); got != 10 {
+		t.Errorf("Expected 10 WARN records in error file, got %d", got)
+	}
+}
+
+func TestRateLimitStatusAndReset(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-rate-status").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithInMemoryTail(100).
+		WithRateLimit(slog.LevelInfo, 5, time.Hour).
+		WithTokenBucketRateLimit(slog.LevelWarn, 0.001, 3)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	status := logger.RateLimitStatus()
+	if got := status[slog.LevelInfo]; got.Used != 0 || got.Remaining != 5 || !got.Reset.IsZero() {
+		t.Errorf("Expected a full INFO budget before logging, got %+v", got)
+	}
+
+	for range 7 {
+		logger.Info("Info message")
+		logger.Warn("Warn message")
+	}
+
+	status = logger.RateLimitStatus()
+	if got := status[slog.LevelInfo]; got.Used != 5 || got.Remaining != 0 || got.Reset.Before(time.Now().Add(59*time.Minute)) {
+		t.Errorf("Expected the INFO window to be used up for about an hour, got %+v", got)
+	}
+	if got := status[slog.LevelWarn]; got.Used != 3 || got.Remaining != 0 || got.Reset.IsZero() {
+		t.Errorf("Expected the WARN bucket to be empty, got %+v", got)
+	}
+	if got := len(logger.Tail()); got != 8 {
+		t.Fatalf("Expected 8 records before the reset, got %d", got)
+	}
+
+	logger.ResetRateLimits()
+
+	status = logger.RateLimitStatus()
+	if got := status[slog.LevelInfo]; got.Remaining != 5 || got.Used != 0 {
+		t.Errorf("Expected a full INFO budget after the reset, got %+v", got)
+	}
+	if got := status[slog.LevelWarn]; got.Remaining != 3 || got.Used != 0 {
+		t.Errorf("Expected a full WARN bucket after the reset, got %+v", got)
+	}
+
+	logger.Info("After reset")
+	logger.Warn("After reset")
+	if got := len(logger.Tail()); got != 10 {
+		t.Errorf("Expected logging to resume after the reset, got %d records", got)
+	}
+}
+
+func TestRateLimitByMessage(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-rate-message").
+		WithLogDir("test-logs-rate-message").
+		WithConsoleOutput(false).
+		WithInMemoryTail(1000).
+		WithRateLimitByMessage(5, time.Hour)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-rate-message")
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				logger.Info("Connection retry")
+				logger.Info("Cache miss")
+			}
+		}()
+	}
+	wg.Wait()
+	logger.Info("Server started")
+
+	counts := make(map[string]int)
+	for _, line := range logger.Tail() {
+		for _, msg := range []string{"Connection retry", "Cache miss", "Server started"} {
+			if strings.Contains(line, msg) {
+				counts[msg]++
+			}
+		}
+	}
+	if counts["Connection retry"] != 5 || counts["Cache miss"] != 5 {
+		t.Errorf("Expected 5 records of each repeating message, got %v", counts)
+	}
+	if counts["Server started"] != 1 {
+		t.Errorf("Expected other messages to keep their own budget, got %v", counts)
+	}
+	if got := logger.Stats().RateLimited; got != 150 {
+		t.Errorf("Expected 150 rate limited records, got %d", got)
+	}
+}
+
+func TestMessageLimiter_SweepsStaleKeys(t *testing.T) {
+	m := &messageLimiter{maxCount: 1, period: int64(time.Second)}
+
+	now := time.Now().UnixNano()
+	m.allow("first", now)
+	m.allow("second", now)
+	if m.allow("first", now) {
+		t.Error("Expected the second record of a message to be limited")
+	}
+
+	// Once the period has passed the next call drops both expired windows
+	later := now + int64(2*time.Second)
+	if !m.allow("third", later) {
+		t.Error("Expected a new message to be allowed")
+	}
+
+	var keys []string
+	m.windows.Range(func(key, value any) bool {
+		keys = append(keys, key.(string))
+		return true
+	})
+	if len(keys) != 1 || keys[0] != "third" {
+		t.Errorf("Expected only the live window to remain, got %v", keys)
+	}
+}
+
+func TestTokenBucketRateLimit(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-token-bucket").
+		WithLogDir("test-logs-token-bucket").
+		WithConsoleOutput(false).
+		WithInMemoryTail(1000).
+		WithRateLimit(slog.LevelInfo, 1, time.Hour).
+		WithTokenBucketRateLimit(slog.LevelInfo, 100, 1) // Replaces the window limit
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-token-bucket")
+
+	if _, exists := logger.config.Filters.RateLimits[slog.LevelInfo]; exists {
+		t.Error("Expected token bucket to replace the window rate limit")
+	}
+
+	start := time.Now()
+	for time.Since(start) < 500*time.Millisecond {
+		logger.Info("Paced message")
+		time.Sleep(time.Millisecond)
+	}
+	elapsed := time.Since(start)
+
+	// One burst token plus 100 tokens per second
+	expected := 1 + elapsed.Seconds()*100
+	got := float64(len(logger.Tail()))
+	if got < expected*0.7 || got > expected*1.1 {
+		t.Errorf("Expected about %.0f records, got %.0f", expected, got)
+	}
+}
+
+func TestGlobalRateLimit(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-global-rate").
+		WithLogDir("test-logs-global-rate").
+		WithConsoleOutput(false).
+		WithInMemoryTail(1000).
+		WithGlobalRateLimit(30, time.Hour)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-global-rate")
+
+	for range 20 {
+		logger.Info("Info message")
+		logger.Warn("Warn message")
+		logger.Error("Error message")
+	}
+
+	if got := len(logger.Tail()); got != 30 {
+		t.Errorf("Expected the global limit to cap records at 30, got %d", got)
+	}
+}
+
+func TestGlobalRateLimit_ErrorBypass(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-global-bypass").
+		WithLogDir("test-logs-global-bypass").
+		WithConsoleOutput(false).
+		WithInMemoryTail(1000).
+		WithGlobalRateLimit(10, time.Hour).
+		WithGlobalRateLimitErrorBypass(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-global-bypass")
+
+	for range 20 {
+		logger.Info("Info message")
+		logger.Error("Error message")
+	}
+
+	var infos, errs int
+	for _, line := range logger.Tail() {
+		switch {
+		case strings.Contains(line, "level=INFO"):
+			infos++
+		case strings.Contains(line, "level=ERROR"):
+			errs++
+		}
+	}
+	if infos != 10 {
+		t.Errorf("Expected 10 INFO records under the global limit, got %d", infos)
+	}
+	if errs != 20 {
+		t.Errorf("Expected all 20 ERROR records to bypass the global limit, got %d", errs)
+	}
+}
+
+func TestAdaptiveSampling(t *testing.T) {
+	const target = 100
+	config := DefaultConfig().
+		WithAppName("test-sampling").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithAdaptiveSampling(target)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	// Burst well above the target for half a second
+	infos, warns := 0, 0
+	for deadline := time.Now().Add(500 * time.Millisecond); time.Now().Before(deadline); {
+		logger.Info("burst")
+		infos++
+		if infos%100 == 0 {
+			logger.Warn("burst warning")
+			warns++
+		}
+	}
+	if infos < 10*target {
+		t.Skipf("Only %d records in the burst, too slow to exceed the target", infos)
+	}
+
+	kept := infos - int(logger.Stats().RateLimited)
+	if kept > target || kept < target/2 {
+		t.Errorf("Expected close to %d of %d INFO records to be kept, got %d", target, infos, kept)
+	}
+
+	// Once the burst is over records pass again
+	time.Sleep(1100 * time.Millisecond)
+	dropped := logger.Stats().RateLimited
+	for range 5 {
+		logger.Info("quiet")
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := logger.Stats().RateLimited; got != dropped {
+		t.Errorf("Expected no sampling below the target rate, got %d more drops", got-dropped)
+	}
+
+	logger.Flush()
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if got := strings.Count(string(content), "burst warning"); got != warns {
+		t.Errorf("Expected all %d warnings to be kept, got %d", warns, got)
+	}
+}
+
+func TestStats_DropCounters(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-stats").
+		WithLogDir("test-logs-stats").
+		WithConsoleOutput(false).
+		WithLevelCondition(slog.LevelWarn).
+		WithRateLimit(slog.LevelWarn, 1, time.Hour).
+		WithFieldRedaction("secret")
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-stats")
+
+	for range 3 {
+		logger.Info("Rejected by condition")
+	}
+	logger.Warn("Allowed", "secret", "hidden")
+	logger.Warn("Rate limited")
+
+	stats := logger.Stats()
+	if stats.ConditionDropped != 3 {
+		t.Errorf("Expected 3 records dropped by conditions, got %d", stats.ConditionDropped)
+	}
+	if stats.RateLimited != 1 {
+		t.Errorf("Expected 1 record dropped by rate limits, got %d", stats.RateLimited)
+	}
+	if stats.FieldsRemoved != 1 {
+		t.Errorf("Expected 1 field removed, got %d", stats.FieldsRemoved)
+	}
+}
+
+func TestLevelOverride(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-level-override").
+		WithLogDir("test-logs-level-override").
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithLogLevel(slog.LevelInfo).
+		WithLevelOverride(AttributeCondition("component", "payments"), slog.LevelDebug)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-level-override")
+
+	logger.Debug("Payment debug", "component", "payments")
+	logger.Debug("Other debug", "component", "search")
+	logger.Debug("Untagged debug")
+	logger.Info("Regular info")
+
+	lines := logger.Tail()
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %d: %v", len(lines), lines)
+	}
+	if !strings.Contains(lines[0], "Payment debug") {
+		t.Errorf("Expected the overridden DEBUG record, got: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "Regular info") {
+		t.Errorf("Expected records at the logger level to be unaffected, got: %s", lines[1])
+	}
+}
+
+func BenchmarkRateLimit_Parallel(b *testing.B) {
+	config := DefaultConfig().
+		WithAppName("bench-rate").
+		WithLogDir("bench-logs-rate").
+		WithConsoleOutput(false).
+		WithRateLimit(slog.LevelInfo, 1000, time.Minute)
+
+	logger, err := New(config)
+	if err != nil {
+		b.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("bench-logs-rate")
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info("Benchmark message", "user", "john")
+		}
+	})
+}
+
+// stringsPC returns a return PC inside the strings package, standing in for
+// a log call made by another package
+func stringsPC() uintptr {
+	var pcs [1]uintptr
+	strings.Map(func(r rune) rune {
+		runtime.Callers(2, pcs[:]) // skip [runtime.Callers, this func]
+		return r
+	}, "x")
+	return pcs[0]
+}
+
+func TestPackageFilter(t *testing.T) {
+	const self = "github.com/sarff/iSlogger"
+
+	tests := []struct {
+		name        string
+		allow, deny []string
+		expected    []string
+	}{
+		{"no filter", nil, nil, []string{"from strings", "from iSlogger"}},
+		{"deny other package", nil, []string{"strings"}, []string{"from iSlogger"}},
+		{"deny own package", nil, []string{self}, []string{"from strings"}},
+		{"allow own package", []string{self}, nil, []string{"from iSlogger"}},
+		{"deny beats allow", []string{self, "strings"}, []string{"strings"}, []string{"from iSlogger"}},
+		{"parent path covers subpackages", nil, []string{"github.com/sarff"}, []string{"from strings"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := DefaultConfig().
+				WithAppName("test-package-filter").
+				WithLogDir(t.TempDir()).
+				WithConsoleOutput(false).
+				WithInMemoryTail(10).
+				WithPackageFilter(test.allow, test.deny)
+
+			logger, err := New(config)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer logger.Close()
+
+			record := slog.NewRecord(time.Now(), slog.LevelInfo, "from strings", stringsPC())
+			logger.Handler().Handle(context.Background(), record)
+			logger.Info("from iSlogger")
+
+			var got []string
+			for _, line := range logger.Tail() {
+				_, msg, _ := strings.Cut(line, "msg=")
+				got = append(got, strings.Trim(msg, `"`))
+			}
+			if strings.Join(got, ",") != strings.Join(test.expected, ",") {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+			if dropped := logger.Stats().ConditionDropped; dropped != uint64(2-len(test.expected)) {
+				t.Errorf("Expected %d dropped records, got %d", 2-len(test.expected), dropped)
+			}
+		})
+	}
+}
+
+func TestFuncPackage(t *testing.T) {
+	tests := map[string]string{
+		"main.main":   "main",
+		"strings.Map": "strings",
+		"github.com/sarff/iSlogger.(*Logger).Info": "github.com/sarff/iSlogger",
+		"example.com/app/db.Query.func1":           "example.com/app/db",
+		"gopkg.in/yaml%2ev3.Unmarshal":             "gopkg.in/yaml.v3",
+	}
+	for function, expected := range tests {
+		if got := funcPackage(function); got != expected {
+			t.Errorf("funcPackage(%q) = %q, expected %q", function, got, expected)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package iSlogger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// gelfVersion is the GELF specification version written in every record
+const gelfVersion = "1.1"
+
+// gelfField is an additional field, already prefixed with an underscore
+type gelfField struct {
+	key   string
+	value any
+}
+
+// gelfHandler writes records as Graylog Extended Log Format JSON, one per line.
+// User attributes become underscore-prefixed additional fields, with groups
+// flattened into dotted names.
+type gelfHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	opts   slog.HandlerOptions
+	host   string
+	fields []gelfField // Fields bound with WithAttrs
+	prefix string      // Dotted group prefix for new attributes
+	groups []string    // Open groups, passed to ReplaceAttr
+}
+
+// newGELFHandler creates a GELF handler writing to w
+func newGELFHandler(w io.Writer, opts *slog.HandlerOptions) *gelfHandler {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	h := &gelfHandler{w: w, mu: &sync.Mutex{}, host: host}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Enabled reports whether the level reaches the configured minimum
+func (h *gelfHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle serializes the record as a single GELF message
+func (h *gelfHandler) Handle(ctx context.Context, record slog.Record) error {
+	msg := map[string]any{
+		"version":       gelfVersion,
+		"host":          h.host,
+		"short_message": record.Message,
+		"timestamp":     float64(record.Time.UnixMilli()) / 1000,
+		"level":         gelfLevel(record.Level),
+	}
+	if record.Time.IsZero() {
+		delete(msg, "timestamp") // Graylog uses the receive time instead
+	}
+
+	if h.opts.AddSource && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		msg["_file"] = frame.File
+		msg["_line"] = frame.Line
+	}
+
+	for _, field := range h.fields {
+		msg[field.key] = field.value
+	}
+	var fields []gelfField
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = h.appendAttr(fields, h.prefix, h.groups, attr)
+		return true
+	})
+	for _, field := range fields {
+		msg[field.key] = field.value
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.w.Write(data)
+	return err
+}
+
+// WithAttrs creates a new handler with additional fields
+func (h *gelfHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.fields = h.fields[:len(h.fields):len(h.fields)] // Appending copies
+	for _, attr := range attrs {
+		clone.fields = h.appendAttr(clone.fields, h.prefix, h.groups, attr)
+	}
+	return &clone
+}
+
+// WithGroup creates a new handler that nests new fields under the group name
+func (h *gelfHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	clone.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &clone
+}
+
+// appendAttr flattens the attribute into underscore-prefixed fields
+func (h *gelfHandler) appendAttr(fields []gelfField, prefix string, groups []string, attr slog.Attr) []gelfField {
+	attr.Value = attr.Value.Resolve()
+	if h.opts.ReplaceAttr != nil && attr.Value.Kind() != slog.KindGroup {
+		attr = h.opts.ReplaceAttr(groups, attr)
+		attr.Value = attr.Value.Resolve()
+	}
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		group := attr.Value.Group()
+		if attr.Key == "" {
+			// Inline groups add their attributes to the current level
+			for _, a := range group {
+				fields = h.appendAttr(fields, prefix, groups, a)
+			}
+			return fields
+		}
+		nestedGroups := append(groups[:len(groups):len(groups)], attr.Key)
+		for _, a := range group {
+			fields = h.appendAttr(fields, prefix+attr.Key+".", nestedGroups, a)
+		}
+		return fields
+	}
+
+	key := "_" + prefix + attr.Key
+	if key == "_id" {
+		key = "__id" // Reserved by GELF
+	}
+	return append(fields, gelfField{key: key, value: gelfValue(attr.Value)})
+}
+
+// gelfValue converts a value to a string or number, the only types GELF allows for fields
+func gelfValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	default:
+		return v.String()
+	}
+}
+
+// gelfLevel maps a slog level to its syslog severity
+func gelfLevel(level slog.Level) int {
+	switch {
+	case level > slog.LevelError:
+		return 2 // Critical
+	case level >= slog.LevelError:
+		return 3 // Error
+	case level >= slog.LevelWarn:
+		return 4 // Warning
+	case level >= slog.LevelInfo:
+		return 6 // Informational
+	default:
+		return 7 // Debug
+	}
+}
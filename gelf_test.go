@@ -0,0 +1,82 @@
+package iSlogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestGELFFormat(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-gelf").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithGELFFormat(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.With("service", "billing").
+		Error("Payment failed", "id", "txn-1", "amount", 42.5, slog.Group("user", "name", "alice"))
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+
+	var msg map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(content), &msg); err != nil {
+		t.Fatalf("Expected one JSON record in the error file, got %q: %v", content, err)
+	}
+
+	host, _ := os.Hostname()
+	expected := map[string]any{
+		"version":       "1.1",
+		"host":          host,
+		"short_message": "Payment failed",
+		"level":         float64(3),
+		"_service":      "billing",
+		"__id":          "txn-1",
+		"_amount":       42.5,
+		"_user.name":    "alice",
+	}
+	for key, value := range expected {
+		if msg[key] != value {
+			t.Errorf("Expected %s = %v, got %v in %s", key, value, msg[key], content)
+		}
+	}
+	if _, ok := msg["timestamp"].(float64); !ok {
+		t.Errorf("Expected a numeric timestamp, got: %s", content)
+	}
+	for _, key := range []string{"msg", "time", "service", "_id"} {
+		if _, exists := msg[key]; exists {
+			t.Errorf("Unexpected key %q in GELF message: %s", key, content)
+		}
+	}
+}
+
+func TestGELFLevel(t *testing.T) {
+	tests := []struct {
+		level    slog.Level
+		expected int
+	}{
+		{slog.LevelDebug, 7},
+		{slog.LevelInfo, 6},
+		{slog.LevelWarn, 4},
+		{slog.LevelError, 3},
+		{slog.Level(12), 2},
+	}
+
+	for _, test := range tests {
+		if got := gelfLevel(test.level); got != test.expected {
+			t.Errorf("gelfLevel(%s) = %d, expected %d", test.level, got, test.expected)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package iSlogger
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"sync"
 )
@@ -9,8 +10,90 @@ import (
 var (
 	defaultLogger *Logger
 	globalMu      sync.RWMutex
+
+	// Live loggers created with Config.Register or RegisterLogger, keyed by their shared core
+	registry   = map[*loggerCore]*Logger{}
+	named      = map[string]*Logger{}
+	registryMu sync.Mutex
 )
 
+// register adds the logger to the registry
+func register(l *Logger) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[l.loggerCore] = l
+}
+
+// unregister removes the logger and any names pointing to it from the registry
+func unregister(l *Logger) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, l.loggerCore)
+	for name, logger := range named {
+		if logger.loggerCore == l.loggerCore {
+			delete(named, name)
+		}
+	}
+}
+
+// RegisterLogger makes the logger retrievable with GetLogger(name) and reachable
+// by FlushAll and CloseAll. A nil logger removes the name.
+func RegisterLogger(name string, l *Logger) {
+	if l != nil {
+		register(l)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if l == nil {
+		delete(named, name)
+		return
+	}
+	named[name] = l
+}
+
+// GetLogger returns the logger registered under name, or nil if there is none
+func GetLogger(name string) *Logger {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return named[name]
+}
+
+// liveLoggers returns the registered loggers and the global logger, each once
+func liveLoggers() []*Logger {
+	global := GetGlobalLogger()
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	loggers := make([]*Logger, 0, len(registry)+1)
+	for _, l := range registry {
+		loggers = append(loggers, l)
+	}
+	if global != nil && registry[global.loggerCore] == nil {
+		loggers = append(loggers, global)
+	}
+	return loggers
+}
+
+// FlushAll flushes the global logger and every registered logger
+func FlushAll() error {
+	var errs []error
+	for _, l := range liveLoggers() {
+		errs = append(errs, l.Flush())
+	}
+	return errors.Join(errs...)
+}
+
+// CloseAll closes the global logger and every registered logger
+func CloseAll() error {
+	errs := []error{Close()}
+	for _, l := range liveLoggers() {
+		errs = append(errs, l.Close())
+	}
+	return errors.Join(errs...)
+}
+
 // Init initializes with a predefined config
 func Init(config Config) error {
 	globalMu.Lock()
@@ -55,7 +138,7 @@ func Debug(msg string, args ...any) {
 	globalMu.RUnlock()
 
 	if logger != nil {
-		logger.Debug(msg, args...)
+		logger.log(slog.LevelDebug, msg, args...)
 	}
 }
 
@@ -66,7 +149,7 @@ func Info(msg string, args ...any) {
 	globalMu.RUnlock()
 
 	if logger != nil {
-		logger.Info(msg, args...)
+		logger.log(slog.LevelInfo, msg, args...)
 	}
 }
 
@@ -77,7 +160,7 @@ func Warn(msg string, args ...any) {
 	globalMu.RUnlock()
 
 	if logger != nil {
-		logger.Warn(msg, args...)
+		logger.log(slog.LevelWarn, msg, args...)
 	}
 }
 
@@ -88,8 +171,24 @@ func Error(msg string, args ...any) {
 	globalMu.RUnlock()
 
 	if logger != nil {
-		logger.Error(msg, args...)
+		logger.log(slog.LevelError, msg, args...)
+	}
+}
+
+// Fatal logs an error message using the global logger, syncs its files and
+// exits the process. Without a global logger it exits with code 1.
+func Fatal(msg string, args ...any) {
+	globalMu.RLock()
+	logger := defaultLogger
+	globalMu.RUnlock()
+
+	if logger == nil {
+		exitFunc(1)
+		return
 	}
+	logger.log(slog.LevelError, msg, args...)
+	logger.Sync()
+	exitFunc(logger.currentConfig().fatalExitCode())
 }
 
 // With creates a logger with additional attributes using the global logger
@@ -140,6 +239,18 @@ func Flush() error {
 	return nil
 }
 
+// Sync flushes and fsyncs the files of the global logger
+func Sync() error {
+	globalMu.RLock()
+	logger := defaultLogger
+	globalMu.RUnlock()
+
+	if logger != nil {
+		return logger.Sync()
+	}
+	return nil
+}
+
 // Close closes the global logger
 func Close() error {
 	globalMu.Lock()
@@ -153,6 +264,19 @@ func Close() error {
 	return nil
 }
 
+// Shutdown gracefully closes the global logger, giving up when ctx expires
+func Shutdown(ctx context.Context) error {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	if defaultLogger != nil {
+		err := defaultLogger.Shutdown(ctx)
+		defaultLogger = nil
+		return err
+	}
+	return nil
+}
+
 // CleanupNow performs immediate cleanup using the global logger
 func CleanupNow() {
 	globalMu.RLock()
@@ -164,6 +288,18 @@ func CleanupNow() {
 	}
 }
 
+// Cleanup synchronously removes old log files using the global logger
+func Cleanup() ([]string, error) {
+	globalMu.RLock()
+	logger := defaultLogger
+	globalMu.RUnlock()
+
+	if logger != nil {
+		return logger.Cleanup()
+	}
+	return nil, nil
+}
+
 // GetLogFiles returns list of log files using the global logger
 func GetLogFiles() ([]string, error) {
 	globalMu.RLock()
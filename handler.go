@@ -2,22 +2,28 @@ package iSlogger
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
-	"sync/atomic"
-	"time"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 // filteredHandler wraps slog.Handler and applies filtering logic
 type filteredHandler struct {
 	handler slog.Handler
 	config  FilterConfig
+	stats   *dropStats // Drop counters, nil if this handler should not count
 }
 
 // newFilteredHandler creates a new filtered handler
-func newFilteredHandler(handler slog.Handler, config FilterConfig) *filteredHandler {
+func newFilteredHandler(handler slog.Handler, config FilterConfig, stats *dropStats) *filteredHandler {
 	return &filteredHandler{
 		handler: handler,
 		config:  config,
+		stats:   stats,
 	}
 }
 
@@ -28,40 +34,113 @@ func (h *filteredHandler) Enabled(ctx context.Context, level slog.Level) bool {
 
 // Handle processes the log record with filtering
 func (h *filteredHandler) Handle(ctx context.Context, record slog.Record) error {
-	// Apply rate limiting first
-	if !h.checkRateLimit(record.Level) {
-		return nil // Skip if rate limited
+	// Nothing to filter, skip attribute extraction entirely
+	if !h.config.hasFilters() {
+		h.countWritten(record.Level)
+		if seq, ok := recordSequence(ctx); ok {
+			record = record.Clone()
+			record.AddAttrs(slog.Uint64(sequenceKey, seq))
+		}
+		return h.handler.Handle(ctx, record)
 	}
 
 	// Extract attributes for condition checking
-	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	attrsBuf := getAttrBuffer()
+	defer putAttrBuffer(attrsBuf)
+	attrs := *attrsBuf
 	record.Attrs(func(attr slog.Attr) bool {
 		attrs = append(attrs, attr)
 		return true
 	})
+	*attrsBuf = attrs
 
 	// Apply conditions
 	if !h.shouldLog(record.Level, record.Message, attrs) {
+		if h.stats != nil {
+			h.stats.conditionDropped.Add(1)
+		}
 		return nil // Skip if conditions not met
 	}
 
+	h.countWritten(record.Level)
+
+	// Keep only the first MaxAttrs attributes; With-bound attributes are exempt
+	dropped := 0
+	if h.config.MaxAttrs > 0 && len(attrs) > h.config.MaxAttrs {
+		dropped = len(attrs) - h.config.MaxAttrs
+		attrs = attrs[:h.config.MaxAttrs]
+	}
+
+	// Sort after the MaxAttrs cut, so the limit keeps the attributes logged first
+	if h.config.AttrSort.enabled {
+		h.config.AttrSort.sort(attrs)
+	}
+
 	// Apply field filters
-	filteredAttrs := h.applyFieldFilters(attrs)
+	filteredBuf := getAttrBuffer()
+	defer putAttrBuffer(filteredBuf)
+	filteredAttrs := h.applyFieldFilters(attrs, *filteredBuf)
+	if dropped > 0 {
+		filteredAttrs = append(filteredAttrs, slog.Int(attrsTruncatedKey, dropped))
+	}
+	// Added last, so neither the filters nor the MaxAttrs limit remove it
+	if seq, ok := recordSequence(ctx); ok {
+		filteredAttrs = append(filteredAttrs, slog.Uint64(sequenceKey, seq))
+	}
+	*filteredBuf = filteredAttrs
 
-	// Create new record with filtered attributes
+	// Create new record with filtered attributes. AddAttrs copies them,
+	// so the pooled buffers are not retained past this call.
 	newRecord := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
-	for _, attr := range filteredAttrs {
-		newRecord.AddAttrs(attr)
-	}
+	newRecord.AddAttrs(filteredAttrs...)
 
 	return h.handler.Handle(ctx, newRecord)
 }
 
-// WithAttrs creates a new handler with additional attributes
+// countWritten counts a record that passed the conditions for the daily summary
+func (h *filteredHandler) countWritten(level slog.Level) {
+	if h.stats != nil {
+		h.stats.levelCounts[levelIndex(level)].Add(1)
+	}
+}
+
+// maxPooledAttrs bounds the capacity of buffers returned to the pool
+const maxPooledAttrs = 256
+
+// attrBufferPool reuses attribute slices across filtered records
+var attrBufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]slog.Attr, 0, 16)
+		return &buf
+	},
+}
+
+// getAttrBuffer returns an empty attribute buffer from the pool
+func getAttrBuffer() *[]slog.Attr {
+	return attrBufferPool.Get().(*[]slog.Attr)
+}
+
+// putAttrBuffer clears the buffer and returns it to the pool
+func putAttrBuffer(buf *[]slog.Attr) {
+	if cap(*buf) > maxPooledAttrs {
+		return // Let oversized buffers be collected
+	}
+	clear(*buf) // Drop references to attribute values
+	*buf = (*buf)[:0]
+	attrBufferPool.Put(buf)
+}
+
+// WithAttrs creates a new handler with additional attributes. Bound attributes are
+// written before the record's, so they are sorted too, as a set of their own.
 func (h *filteredHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if h.config.AttrSort.enabled {
+		attrs = slices.Clone(attrs)
+		h.config.AttrSort.sort(attrs)
+	}
 	return &filteredHandler{
 		handler: h.handler.WithAttrs(attrs),
 		config:  h.config,
+		stats:   h.stats,
 	}
 }
 
@@ -70,51 +149,89 @@ func (h *filteredHandler) WithGroup(name string) slog.Handler {
 	return &filteredHandler{
 		handler: h.handler.WithGroup(name),
 		config:  h.config,
+		stats:   h.stats,
 	}
 }
 
 // shouldLog checks if the log entry should be written based on conditions
 func (h *filteredHandler) shouldLog(level slog.Level, msg string, attrs []slog.Attr) bool {
-	// If no conditions are set, log everything
-	if len(h.config.Conditions) == 0 {
-		return true
+	return h.config.conditionsPass(level, msg, attrs)
+}
+
+// applyFieldFilters applies field filters to attributes, appending the result to dst
+func (h *filteredHandler) applyFieldFilters(attrs, dst []slog.Attr) []slog.Attr {
+	if len(h.config.FieldFilters) == 0 && len(h.config.RegexFilters) == 0 &&
+		h.config.MaxValueLength <= 0 && !h.config.DropEmptyAttrs && !h.config.SanitizeUTF8 {
+		return append(dst, attrs...)
 	}
 
-	// All conditions must pass (AND logic)
-	for _, condition := range h.config.Conditions {
-		if !condition(level, msg, attrs) {
-			return false
+	filtered := dst
+	truncated := false
+	for _, attr := range attrs {
+		if filteredAttr, keep := h.filterAttr("", attr, &truncated); keep {
+			filtered = append(filtered, filteredAttr)
 		}
 	}
-	return true
+	if truncated {
+		filtered = append(filtered, slog.Bool(truncatedKey, true))
+	}
+	return filtered
 }
 
-// applyFieldFilters applies field filters to attributes
-func (h *filteredHandler) applyFieldFilters(attrs []slog.Attr) []slog.Attr {
-	if len(h.config.FieldFilters) == 0 && len(h.config.RegexFilters) == 0 {
-		return attrs
+// filterAttr applies filters to the attribute and, for groups, to every nested
+// attribute, then truncates values over MaxValueLength, setting truncated if any
+// was cut. It reports false if the attribute was redacted or dropped as empty.
+func (h *filteredHandler) filterAttr(prefix string, attr slog.Attr, truncated *bool) (slog.Attr, bool) {
+	attr = h.applyFiltersToAttr(prefix, attr)
+
+	if value := attr.Value.Resolve(); value.Kind() == slog.KindGroup {
+		nestedPrefix := prefix
+		if attr.Key != "" {
+			nestedPrefix = prefix + attr.Key + "."
+		}
+		nested := make([]slog.Attr, 0, len(value.Group()))
+		for _, a := range value.Group() {
+			if a, keep := h.filterAttr(nestedPrefix, a, truncated); keep {
+				nested = append(nested, a)
+			}
+		}
+		attr.Value = slog.GroupValue(nested...)
+	} else if h.config.MaxValueLength > 0 {
+		// After masking and regex filters, so a redaction is never cut
+		var cut bool
+		attr, cut = truncateAttr(attr, h.config.MaxValueLength)
+		*truncated = *truncated || cut
 	}
 
-	filtered := make([]slog.Attr, 0, len(attrs))
-	for _, attr := range attrs {
-		filteredAttr := h.applyFiltersToAttr(attr)
-		if filteredAttr.Value.String() != "" { // Only include non-empty values
-			filtered = append(filtered, filteredAttr)
+	if isRedacted(attr.Value) || (h.config.DropEmptyAttrs && isEmptyValue(attr.Value.Resolve())) {
+		if h.stats != nil {
+			h.stats.fieldsRemoved.Add(1)
 		}
+		return attr, false
 	}
-	return filtered
+	return attr, true
 }
 
-// applyFiltersToAttr applies filters to a single attribute
-func (h *filteredHandler) applyFiltersToAttr(attr slog.Attr) slog.Attr {
+// applyFiltersToAttr applies filters to a single attribute. Field filters match
+// the dotted path within groups (e.g. "user.password") or the bare key.
+func (h *filteredHandler) applyFiltersToAttr(prefix string, attr slog.Attr) slog.Attr {
+	// Resolve LogValuers first, so strings they produce don't bypass the regex filters.
+	// Values keep their kind: only field filters and regex matches on strings change them.
+	attr.Value = attr.Value.Resolve()
+
 	// Apply field-specific filters
-	if filter, exists := h.config.FieldFilters[attr.Key]; exists {
+	if filter, exists := h.config.FieldFilters[prefix+attr.Key]; exists {
+		attr.Value = filter(attr.Key, attr.Value)
+	} else if filter, exists := h.config.FieldFilters[attr.Key]; exists && prefix != "" {
 		attr.Value = filter(attr.Key, attr.Value)
 	}
 
-	// Apply regex filters to string values
+	// Apply regex filters to string values, after making them valid UTF-8
 	if attr.Value.Kind() == slog.KindString {
 		strVal := attr.Value.String()
+		if h.config.SanitizeUTF8 && !utf8.ValidString(strVal) {
+			strVal = strings.ToValidUTF8(strVal, "\uFFFD")
+		}
 		for _, regexFilter := range h.config.RegexFilters {
 			strVal = regexFilter.Pattern.ReplaceAllString(strVal, regexFilter.Replacement)
 		}
@@ -124,32 +241,351 @@ func (h *filteredHandler) applyFiltersToAttr(attr slog.Attr) slog.Attr {
 	return attr
 }
 
-// checkRateLimit checks if the log entry should be rate limited
-func (h *filteredHandler) checkRateLimit(level slog.Level) bool {
-	rateLimitPtr, exists := h.config.RateLimits[level]
-	if !exists {
-		return true // No rate limit set, allow
+// attrsTruncatedKey holds the number of attributes dropped by the MaxAttrs limit
+const attrsTruncatedKey = "attrs_truncated"
+
+// sequenceKey holds the record's sequence number when SequenceNumbers is enabled
+const sequenceKey = "seq"
+
+// sequenceContextKey is the context key carrying a record's sequence number from
+// the logger to its filtered handlers
+type sequenceContextKey struct{}
+
+// recordSequence returns the sequence number assigned to the record being handled
+func recordSequence(ctx context.Context) (uint64, bool) {
+	seq, ok := ctx.Value(sequenceContextKey{}).(uint64)
+	return seq, ok
+}
+
+// truncatedKey marks records that had at least one attribute value truncated
+const truncatedKey = "_truncated"
+
+// truncateAttr shortens string and stringified values longer than maxLen bytes
+func truncateAttr(attr slog.Attr, maxLen int) (slog.Attr, bool) {
+	value := attr.Value.Resolve()
+	if value.Kind() != slog.KindString && value.Kind() != slog.KindAny {
+		return attr, false
+	}
+
+	str := value.String()
+	if len(str) <= maxLen {
+		return attr, false
 	}
 
-	// Make a copy to work with
-	rateLimit := rateLimitPtr
-	now := time.Now()
+	// Cut on a rune boundary so multi-byte characters are not split
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(str[cut]) {
+		cut--
+	}
+	attr.Value = slog.StringValue(str[:cut] + "...")
+	return attr, true
+}
+
+// newFormatHandler creates a base slog handler for the given output format
+func newFormatHandler(w io.Writer, format OutputFormat, opts *slog.HandlerOptions) slog.Handler {
+	switch format {
+	case FormatJSON:
+		return slog.NewJSONHandler(w, opts)
+	case FormatGELF:
+		return newGELFHandler(w, opts)
+	case FormatLogfmt:
+		return newLogfmtHandler(w, opts)
+	default:
+		return slog.NewTextHandler(w, opts)
+	}
+}
 
-	// Check if we need to reset the counter
-	if now.Sub(rateLimit.lastReset) >= rateLimit.Period {
-		atomic.StoreInt64(&rateLimit.counter, 0)
-		rateLimit.lastReset = now
-		// Update the config map
-		h.config.RateLimits[level] = rateLimit
+// formatHandler creates a handler for the format, writing multi-line text
+// values on continuation lines when MultilineValues is set
+func (c Config) formatHandler(w io.Writer, format OutputFormat, opts *slog.HandlerOptions) slog.Handler {
+	if c.MultilineValues && format == FormatText {
+		return newMultilineHandler(w, opts)
 	}
+	return newFormatHandler(w, format, opts)
+}
+
+// multiHandler fans out each record to several handlers
+type multiHandler struct {
+	handlers []slog.Handler
+}
+
+// newMultiHandler creates a handler that writes to all given handlers
+func newMultiHandler(handlers ...slog.Handler) *multiHandler {
+	return &multiHandler{handlers: handlers}
+}
+
+// Enabled reports whether any of the handlers is enabled for the level
+func (h *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle passes the record to every enabled handler
+func (h *multiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, record.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs creates a new multi handler with additional attributes
+func (h *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+// WithGroup creates a new multi handler with a group
+func (h *multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+// loggerHandler exposes a Logger as a standard slog.Handler
+type loggerHandler struct {
+	logger *Logger
+}
+
+// Handler returns an slog.Handler that writes through this logger,
+// keeping the info/error file split, filters and buffering
+func (l *Logger) Handler() slog.Handler {
+	return &loggerHandler{logger: l}
+}
+
+// Slog returns a standard *slog.Logger backed by this logger
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(l.Handler())
+}
+
+// Enabled checks if the logger is enabled for the given level
+func (h *loggerHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger.enabled(ctx, level)
+}
+
+// Handle writes the record to the info file and, for WARN and above, to the error file
+func (h *loggerHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.logger.handle(ctx, record)
+}
 
-	// Check if we're under the limit
-	current := atomic.AddInt64(&rateLimit.counter, 1)
-	if current <= int64(rateLimit.MaxCount) {
-		// Update the config map
-		h.config.RateLimits[level] = rateLimit
+// WithAttrs creates a new handler with additional attributes
+func (h *loggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &loggerHandler{logger: h.logger.derive(chainStep{attrs: attrs})}
+}
+
+// WithGroup creates a new handler with a group
+func (h *loggerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &loggerHandler{logger: h.logger.derive(chainStep{group: name})}
+}
+
+// belowLevelHandler drops records at or above limit. It stays enabled for them
+// so the stream it belongs to still sees and filters every record.
+type belowLevelHandler struct {
+	handler slog.Handler
+	limit   slog.Level
+}
+
+// Enabled reports whether the wrapped handler is enabled for the level
+func (h *belowLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle writes the record only if it is below the limit
+func (h *belowLevelHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= h.limit {
+		return nil
+	}
+	return h.handler.Handle(ctx, record)
+}
+
+// WithAttrs creates a new handler with additional attributes
+func (h *belowLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &belowLevelHandler{handler: h.handler.WithAttrs(attrs), limit: h.limit}
+}
+
+// WithGroup creates a new handler with a group
+func (h *belowLevelHandler) WithGroup(name string) slog.Handler {
+	return &belowLevelHandler{handler: h.handler.WithGroup(name), limit: h.limit}
+}
+
+// minLevelHandler drops records below min, e.g. to keep lower levels off stderr
+type minLevelHandler struct {
+	handler slog.Handler
+	min     slog.Level
+}
+
+// Enabled reports whether the level reaches min and the wrapped handler is enabled
+func (h *minLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.min && h.handler.Enabled(ctx, level)
+}
+
+// Handle writes the record only if it reaches min
+func (h *minLevelHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level < h.min {
+		return nil
+	}
+	return h.handler.Handle(ctx, record)
+}
+
+// WithAttrs creates a new handler with additional attributes
+func (h *minLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &minLevelHandler{handler: h.handler.WithAttrs(attrs), min: h.min}
+}
+
+// WithGroup creates a new handler with a group
+func (h *minLevelHandler) WithGroup(name string) slog.Handler {
+	return &minLevelHandler{handler: h.handler.WithGroup(name), min: h.min}
+}
+
+// levelKinds names the per-level files, ordered by levelIndex
+var levelKinds = [...]string{"debug", "info", "warn", "error"}
+
+// levelIndex maps a level to its position in levelKinds
+func levelIndex(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3
+	case level >= slog.LevelWarn:
+		return 2
+	case level >= slog.LevelInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// levelRouterHandler sends each record to the handler registered for its level
+type levelRouterHandler struct {
+	handlers [len(levelKinds)]slog.Handler // nil entries drop the record
+}
+
+// newLevelRouterHandler creates a handler routing by levelIndex
+func newLevelRouterHandler(handlers [len(levelKinds)]slog.Handler) *levelRouterHandler {
+	return &levelRouterHandler{handlers: handlers}
+}
+
+// Enabled checks if the handler for the level exists and is enabled
+func (h *levelRouterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	handler := h.handlers[levelIndex(level)]
+	return handler != nil && handler.Enabled(ctx, level)
+}
+
+// Handle passes the record to the handler for its level
+func (h *levelRouterHandler) Handle(ctx context.Context, record slog.Record) error {
+	handler := h.handlers[levelIndex(record.Level)]
+	if handler == nil {
+		return nil
+	}
+	return handler.Handle(ctx, record)
+}
+
+// WithAttrs creates a new router with additional attributes
+func (h *levelRouterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var handlers [len(levelKinds)]slog.Handler
+	for i, handler := range h.handlers {
+		if handler != nil {
+			handlers[i] = handler.WithAttrs(attrs)
+		}
+	}
+	return &levelRouterHandler{handlers: handlers}
+}
+
+// WithGroup creates a new router with a group
+func (h *levelRouterHandler) WithGroup(name string) slog.Handler {
+	var handlers [len(levelKinds)]slog.Handler
+	for i, handler := range h.handlers {
+		if handler != nil {
+			handlers[i] = handler.WithGroup(name)
+		}
+	}
+	return &levelRouterHandler{handlers: handlers}
+}
+
+// flattenHandler rewrites grouped attributes into flat keys joined by a separator,
+// for downstream parsers that can't handle nested objects. Groups opened with
+// WithGroup become key prefixes instead of being passed to the wrapped handler.
+type flattenHandler struct {
+	handler   slog.Handler
+	separator string
+	prefix    string // Open groups, each followed by the separator
+}
+
+// newFlattenHandler creates a handler flattening groups with the given separator
+func newFlattenHandler(handler slog.Handler, separator string) *flattenHandler {
+	return &flattenHandler{handler: handler, separator: separator}
+}
+
+// Enabled reports whether the wrapped handler is enabled for the level
+func (h *flattenHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle writes the record with its attributes flattened
+func (h *flattenHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrsBuf := getAttrBuffer()
+	defer putAttrBuffer(attrsBuf)
+	attrs := *attrsBuf
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = h.appendFlattened(attrs, h.prefix, attr)
 		return true
+	})
+	*attrsBuf = attrs
+
+	newRecord := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	newRecord.AddAttrs(attrs...)
+	return h.handler.Handle(ctx, newRecord)
+}
+
+// WithAttrs creates a new handler with additional flattened attributes
+func (h *flattenHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var flattened []slog.Attr
+	for _, attr := range attrs {
+		flattened = h.appendFlattened(flattened, h.prefix, attr)
+	}
+	return &flattenHandler{handler: h.handler.WithAttrs(flattened), separator: h.separator, prefix: h.prefix}
+}
+
+// WithGroup creates a new handler that prefixes new keys with the group name
+func (h *flattenHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &flattenHandler{handler: h.handler, separator: h.separator, prefix: h.prefix + name + h.separator}
+}
+
+// appendFlattened appends the attribute with its key prefixed, descending into groups
+func (h *flattenHandler) appendFlattened(dst []slog.Attr, prefix string, attr slog.Attr) []slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return dst
+	}
+	if attr.Value.Kind() != slog.KindGroup {
+		attr.Key = prefix + attr.Key
+		return append(dst, attr)
 	}
 
-	return false // Rate limited
+	if attr.Key != "" {
+		prefix += attr.Key + h.separator
+	}
+	for _, a := range attr.Value.Group() {
+		dst = h.appendFlattened(dst, prefix, a)
+	}
+	return dst
 }
@@ -0,0 +1,230 @@
+package iSlogger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-slog").
+		WithLogDir("test-logs-slog").
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithFieldMask("password", "***")
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-slog")
+
+	sl := slog.New(logger.Handler()).With("service", "api").WithGroup("req")
+	if sl.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Expected DEBUG to be disabled at INFO level")
+	}
+
+	sl.Debug("Hidden debug")
+	sl.Info("Slog info", "password", "secret")
+	sl.Warn("Slog warning", "id", 7)
+
+	infoPath, errorPath := logger.GetCurrentLogPaths()
+	infoContent, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	info := string(infoContent)
+	if strings.Contains(info, "Hidden debug") {
+		t.Error("Expected debug record to be dropped")
+	}
+	if !strings.Contains(info, `msg="Slog info" service=api req.password=***`) {
+		t.Errorf("Expected filtered info record with attrs and group, got: %s", info)
+	}
+	if strings.Contains(info, "Slog warning") {
+		t.Error("Expected warning to be kept out of the info file")
+	}
+
+	errorContent, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if !strings.Contains(string(errorContent), `level=WARN msg="Slog warning" service=api req.id=7`) {
+		t.Errorf("Expected warning in error file, got: %s", errorContent)
+	}
+}
+
+func TestSlog(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-slog-default").
+		WithLogDir("test-logs-slog-default").
+		WithConsoleOutput(false).
+		WithoutBuffering()
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-slog-default")
+
+	logger.Slog().Error("Slog error", "code", 500)
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if !strings.Contains(string(content), `level=ERROR msg="Slog error" code=500`) {
+		t.Errorf("Expected error record in error file, got: %s", content)
+	}
+}
+
+func TestFilteredHandler_ConcurrentPooling(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-pool").
+		WithLogDir("test-logs-pool").
+		WithConsoleOutput(false).
+		WithInMemoryTail(2000).
+		WithFieldMask("password", "***")
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-pool")
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				id := fmt.Sprintf("%d-%d", g, i)
+				logger.Info("Record", "id", id, "password", "secret", "echo", id)
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := logger.Tail()
+	if len(lines) != 1600 {
+		t.Fatalf("Expected 1600 records, got %d", len(lines))
+	}
+	for _, line := range lines {
+		var id, echo string
+		for field := range strings.FieldsSeq(line) {
+			if v, ok := strings.CutPrefix(field, "id="); ok {
+				id = v
+			}
+			if v, ok := strings.CutPrefix(field, "echo="); ok {
+				echo = v
+			}
+		}
+		if id == "" || id != echo || !strings.Contains(line, "password=***") {
+			t.Fatalf("Corrupted record: %s", line)
+		}
+	}
+}
+
+func BenchmarkFilteredHandler_NoFilters(b *testing.B) {
+	handler := newFilteredHandler(slog.NewTextHandler(io.Discard, nil), DefaultFilterConfig(), nil)
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "Benchmark message", 0)
+	record.AddAttrs(slog.String("user", "john"), slog.Int("attempt", 3), slog.Bool("ok", true))
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		handler.Handle(ctx, record)
+	}
+}
+
+func BenchmarkFilteredHandler_WithFilters(b *testing.B) {
+	config := DefaultConfig().
+		WithFieldMask("password", "***").
+		WithRegexFilter(`\d{4}-\d{4}-\d{4}-\d{4}`, "****-****-****-****")
+	handler := newFilteredHandler(slog.NewTextHandler(io.Discard, nil), config.Filters, nil)
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "Benchmark message", 0)
+	record.AddAttrs(
+		slog.String("user", "john"),
+		slog.String("password", "secret"),
+		slog.String("card", "1234-5678-9012-3456"),
+		slog.Int("attempt", 3),
+		slog.Bool("ok", true),
+		slog.String("region", "eu"),
+	)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		handler.Handle(ctx, record)
+	}
+}
+
+// enrichHandler adds a fixed attribute to every record, as a user middleware would
+type enrichHandler struct {
+	slog.Handler
+	attr slog.Attr
+}
+
+func (h enrichHandler) Handle(ctx context.Context, record slog.Record) error {
+	record.AddAttrs(h.attr)
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h enrichHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return enrichHandler{Handler: h.Handler.WithAttrs(attrs), attr: h.attr}
+}
+
+func (h enrichHandler) WithGroup(name string) slog.Handler {
+	return enrichHandler{Handler: h.Handler.WithGroup(name), attr: h.attr}
+}
+
+func TestHandlerMiddleware(t *testing.T) {
+	wrapped := 0
+	config := DefaultConfig().
+		WithAppName("test-middleware").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithFieldMask("password", "***").
+		WithHandlerMiddleware(func(next slog.Handler) slog.Handler {
+			return enrichHandler{Handler: next, attr: slog.String("region", "eu-west-1")}
+		}).
+		WithHandlerMiddleware(func(next slog.Handler) slog.Handler {
+			return enrichHandler{Handler: next, attr: slog.String("zone", "b")}
+		}).
+		WithHandlerMiddleware(func(next slog.Handler) slog.Handler {
+			wrapped++
+			return next
+		})
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.With("service", "api").Error("Login failed", "password", "secret")
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	// Filters run before the middleware, and the first middleware added runs first
+	if !strings.Contains(string(content), `service=api password=*** region=eu-west-1 zone=b`) {
+		t.Errorf("Expected the middleware attributes after the filtered ones, got: %s", content)
+	}
+	if wrapped != 2 {
+		t.Errorf("Expected the middleware to wrap the info and error streams, got %d calls", wrapped)
+	}
+}
@@ -0,0 +1,120 @@
+// Package http provides HTTP middleware that attaches a request-scoped
+// iSlogger logger to every request.
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	nethttp "net/http"
+	"time"
+
+	"github.com/sarff/iSlogger"
+)
+
+// RequestIDHeader is the header used to read and propagate request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// contextKey is the context key type for the request logger
+type contextKey struct{}
+
+// Middleware logs the start and completion of every request and stores a
+// request-scoped logger with method, path, remote_addr and request_id in the
+// request context. The completion record is logged at WARN for 4xx and at
+// ERROR for 5xx responses.
+func Middleware(logger *iSlogger.Logger) func(nethttp.Handler) nethttp.Handler {
+	return func(next nethttp.Handler) nethttp.Handler {
+		return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" {
+				requestID = NewRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			requestLogger := logger.With(
+				"method", r.Method,
+				"path", r.URL.Path,
+				"remote_addr", r.RemoteAddr,
+				"request_id", requestID,
+			)
+			requestLogger.Info("Request started")
+
+			// Capture the status code written by the handler
+			wrapper := &responseWriter{
+				ResponseWriter: w,
+				statusCode:     nethttp.StatusOK,
+			}
+
+			next.ServeHTTP(wrapper, r.WithContext(NewContext(r.Context(), requestLogger)))
+
+			args := []any{
+				"status", wrapper.statusCode,
+				iSlogger.Duration("duration_ms", time.Since(start)),
+			}
+			switch {
+			case wrapper.statusCode >= 500:
+				requestLogger.Error("Request completed", args...)
+			case wrapper.statusCode >= 400:
+				requestLogger.Warn("Request completed", args...)
+			default:
+				requestLogger.Info("Request completed", args...)
+			}
+		})
+	}
+}
+
+// NewContext returns a copy of ctx carrying the logger
+func NewContext(ctx context.Context, logger *iSlogger.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request logger stored by Middleware. It falls back to
+// the global logger, or a no-op logger if none is initialized.
+func FromContext(ctx context.Context) *iSlogger.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*iSlogger.Logger); ok {
+		return logger
+	}
+	if logger := iSlogger.GetGlobalLogger(); logger != nil {
+		return logger
+	}
+	return iSlogger.NewNop()
+}
+
+// NewRequestID returns a random 128-bit request ID encoded as 32 hex characters
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:]) // Never returns an error
+
+	var buf [32]byte
+	hex.Encode(buf[:], b[:])
+	return string(buf[:])
+}
+
+// responseWriter wraps http.ResponseWriter to capture the status code
+type responseWriter struct {
+	nethttp.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+}
+
+// WriteHeader records the first status code written
+func (rw *responseWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.statusCode = code
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Write marks the header as written with the default status
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (rw *responseWriter) Unwrap() nethttp.ResponseWriter {
+	return rw.ResponseWriter
+}
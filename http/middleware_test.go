@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sarff/iSlogger"
+)
+
+func TestMiddleware(t *testing.T) {
+	config := iSlogger.DefaultConfig().
+		WithAppName("test-http").
+		WithLogDir("test-logs-http").
+		WithConsoleOutput(false).
+		WithInMemoryTail(10)
+
+	logger, err := iSlogger.New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-http")
+
+	handler := Middleware(logger)(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		FromContext(r.Context()).Info("Handling request")
+		w.WriteHeader(nethttp.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(nethttp.MethodPost, "/users", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(RequestIDHeader); got != "req-123" {
+		t.Errorf("Expected request ID header req-123, got %q", got)
+	}
+
+	lines := logger.Tail()
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 records, got %d: %v", len(lines), lines)
+	}
+
+	for _, line := range lines {
+		if !strings.Contains(line, "method=POST path=/users remote_addr=192.0.2.1:1234 request_id=req-123") {
+			t.Errorf("Expected request attributes, got: %s", line)
+		}
+	}
+	if !strings.Contains(lines[1], `msg="Handling request"`) {
+		t.Errorf("Expected handler record from the context logger, got: %s", lines[1])
+	}
+	if !strings.Contains(lines[2], `msg="Request completed"`) ||
+		!strings.Contains(lines[2], "status=201") ||
+		!strings.Contains(lines[2], "duration_ms=") {
+		t.Errorf("Expected completion record with status and duration, got: %s", lines[2])
+	}
+}
+
+func TestMiddleware_ServerError(t *testing.T) {
+	config := iSlogger.DefaultConfig().
+		WithAppName("test-http-error").
+		WithLogDir("test-logs-http-error").
+		WithConsoleOutput(false).
+		WithInMemoryTail(10)
+
+	logger, err := iSlogger.New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-http-error")
+
+	handler := Middleware(logger)(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		nethttp.Error(w, "boom", nethttp.StatusInternalServerError)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(nethttp.MethodGet, "/", nil))
+
+	lines := logger.Tail()
+	last := lines[len(lines)-1]
+	if !strings.Contains(last, "level=ERROR") || !strings.Contains(last, "status=500") {
+		t.Errorf("Expected ERROR completion record with status 500, got: %s", last)
+	}
+	if !strings.Contains(last, "request_id=") {
+		t.Errorf("Expected a generated request ID, got: %s", last)
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	const goroutines, perGoroutine = 8, 1000
+
+	ids := make(chan string, goroutines*perGoroutine)
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perGoroutine {
+				ids <- NewRequestID()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool, goroutines*perGoroutine)
+	for id := range ids {
+		if len(id) != 32 {
+			t.Fatalf("Expected 32 hex characters, got %q", id)
+		}
+		if seen[id] {
+			t.Fatalf("Duplicate request ID %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func BenchmarkNewRequestID(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		NewRequestID()
+	}
+}
+
+func TestFromContext_Fallback(t *testing.T) {
+	if FromContext(context.Background()) == nil {
+		t.Error("Expected a fallback logger when the context has none")
+	}
+}
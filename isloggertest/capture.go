@@ -0,0 +1,149 @@
+// Package isloggertest provides helpers for asserting on log output in tests.
+package isloggertest
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sarff/iSlogger"
+)
+
+// Entry is a single captured record. Grouped attribute keys are joined with dots.
+type Entry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   map[string]any
+}
+
+// Capture records every entry emitted by the logger returned from NewCapture
+type Capture struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewCapture returns a logger that keeps its records in memory instead of
+// writing files, together with the Capture holding them. All levels are captured.
+func NewCapture() (*iSlogger.Logger, *Capture) {
+	c := &Capture{}
+	return iSlogger.NewWithHandler(&captureHandler{capture: c}), c
+}
+
+// Entries returns a copy of the captured entries, oldest first
+func (c *Capture) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]Entry, len(c.entries))
+	copy(entries, c.entries)
+	return entries
+}
+
+// Reset discards all captured entries
+func (c *Capture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+// Logged reports whether an entry at level with a message containing msgSubstring was captured
+func (c *Capture) Logged(level slog.Level, msgSubstring string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, entry := range c.entries {
+		if entry.Level == level && strings.Contains(entry.Message, msgSubstring) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertLogged fails the test if no entry at level with a message containing msgSubstring was captured
+func (c *Capture) AssertLogged(t testing.TB, level slog.Level, msgSubstring string) {
+	t.Helper()
+	if !c.Logged(level, msgSubstring) {
+		t.Errorf("Expected a %s record containing %q, got %d entries", level, msgSubstring, len(c.Entries()))
+	}
+}
+
+// add appends an entry
+func (c *Capture) add(entry Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = append(c.entries, entry)
+}
+
+// captureHandler is a slog.Handler that stores records in a Capture
+type captureHandler struct {
+	capture *Capture
+	attrs   []slog.Attr // Attributes bound with WithAttrs, keys already prefixed
+	prefix  string      // Dotted group prefix for record attributes
+}
+
+// Enabled captures every level
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle stores the record with its bound and own attributes
+func (h *captureHandler) Handle(_ context.Context, record slog.Record) error {
+	attrs := make(map[string]any, len(h.attrs)+record.NumAttrs())
+	for _, attr := range h.attrs {
+		addAttr(attrs, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addAttr(attrs, h.prefix, attr)
+		return true
+	})
+
+	h.capture.add(Entry{
+		Time:    record.Time,
+		Level:   record.Level,
+		Message: record.Message,
+		Attrs:   attrs,
+	})
+	return nil
+}
+
+// WithAttrs binds attributes under the current group prefix
+func (h *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	bound := make([]slog.Attr, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(bound, h.attrs)
+	for _, attr := range attrs {
+		attr.Key = h.prefix + attr.Key
+		bound = append(bound, attr)
+	}
+	return &captureHandler{capture: h.capture, attrs: bound, prefix: h.prefix}
+}
+
+// WithGroup nests subsequent attributes under name
+func (h *captureHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &captureHandler{capture: h.capture, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// addAttr stores attr in attrs, flattening groups into dotted keys
+func addAttr(attrs map[string]any, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, groupAttr := range value.Group() {
+			addAttr(attrs, groupPrefix, groupAttr)
+		}
+		return
+	}
+	if attr.Key == "" {
+		return
+	}
+	attrs[prefix+attr.Key] = value.Any()
+}
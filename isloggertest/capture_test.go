@@ -0,0 +1,59 @@
+package isloggertest
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+func TestCapture(t *testing.T) {
+	logger, capture := NewCapture()
+
+	logger.Debug("Cache warmed")
+	logger.With("user_id", 42).Warn("Login failed", "attempt", 3)
+
+	capture.AssertLogged(t, slog.LevelDebug, "warmed")
+	capture.AssertLogged(t, slog.LevelWarn, "Login failed")
+
+	if capture.Logged(slog.LevelError, "Login failed") {
+		t.Error("Expected no ERROR record")
+	}
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if got := entries[1].Attrs["user_id"]; got != int64(42) {
+		t.Errorf("Expected bound user_id=42, got %v", got)
+	}
+	if got := entries[1].Attrs["attempt"]; got != int64(3) {
+		t.Errorf("Expected attempt=3, got %v", got)
+	}
+
+	capture.Reset()
+	if len(capture.Entries()) != 0 {
+		t.Error("Expected no entries after Reset")
+	}
+}
+
+func TestCapture_Groups(t *testing.T) {
+	logger, capture := NewCapture()
+
+	logger.Slog().WithGroup("http").Info("Request", "status", 200, slog.Group("client", "ip", "10.0.0.1"))
+
+	attrs := capture.Entries()[0].Attrs
+	if attrs["http.status"] != int64(200) || attrs["http.client.ip"] != "10.0.0.1" {
+		t.Errorf("Expected dotted group keys, got %v", attrs)
+	}
+}
+
+func ExampleNewCapture() {
+	logger, capture := NewCapture()
+
+	logger.Info("User created", "user_id", 7)
+
+	for _, entry := range capture.Entries() {
+		fmt.Println(entry.Level, entry.Message, entry.Attrs["user_id"])
+	}
+	// Output: INFO User created 7
+}
@@ -0,0 +1,169 @@
+package iSlogger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// logfmtHandler writes records as strict logfmt, one per line. Values are
+// quoted only when needed, groups are flattened into dotted keys and times
+// are written as RFC3339.
+type logfmtHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	opts   slog.HandlerOptions
+	bound  []byte   // Pre-rendered pairs from WithAttrs, each with a leading space
+	prefix string   // Dotted group prefix for new attributes
+	groups []string // Open groups, passed to ReplaceAttr
+}
+
+// newLogfmtHandler creates a logfmt handler writing to w
+func newLogfmtHandler(w io.Writer, opts *slog.HandlerOptions) *logfmtHandler {
+	h := &logfmtHandler{w: w, mu: &sync.Mutex{}}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Enabled reports whether the level reaches the configured minimum
+func (h *logfmtHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle writes the record as a single logfmt line
+func (h *logfmtHandler) Handle(ctx context.Context, record slog.Record) error {
+	var buf []byte
+	if !record.Time.IsZero() {
+		buf = h.appendAttr(buf, "", nil, slog.Time(slog.TimeKey, record.Time))
+	}
+	buf = h.appendAttr(buf, "", nil, slog.Any(slog.LevelKey, record.Level))
+	if h.opts.AddSource && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		source := &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+		buf = h.appendAttr(buf, "", nil, slog.Any(slog.SourceKey, source))
+	}
+	buf = h.appendAttr(buf, "", nil, slog.String(slog.MessageKey, record.Message))
+
+	buf = append(buf, h.bound...)
+	record.Attrs(func(attr slog.Attr) bool {
+		buf = h.appendAttr(buf, h.prefix, h.groups, attr)
+		return true
+	})
+
+	// Every pair starts with a space; drop the first one
+	if len(buf) > 0 {
+		buf = buf[1:]
+	}
+	buf = append(buf, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf)
+	return err
+}
+
+// WithAttrs creates a new handler with additional pre-rendered pairs
+func (h *logfmtHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.bound = h.bound[:len(h.bound):len(h.bound)] // Appending copies
+	for _, attr := range attrs {
+		clone.bound = h.appendAttr(clone.bound, h.prefix, h.groups, attr)
+	}
+	return &clone
+}
+
+// WithGroup creates a new handler that nests new keys under the group name
+func (h *logfmtHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	clone.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &clone
+}
+
+// appendAttr appends " key=value" for the attribute, flattening groups
+func (h *logfmtHandler) appendAttr(buf []byte, prefix string, groups []string, attr slog.Attr) []byte {
+	attr.Value = attr.Value.Resolve()
+	if h.opts.ReplaceAttr != nil && attr.Value.Kind() != slog.KindGroup {
+		attr = h.opts.ReplaceAttr(groups, attr)
+		attr.Value = attr.Value.Resolve()
+	}
+	if attr.Equal(slog.Attr{}) {
+		return buf
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		nestedPrefix, nestedGroups := prefix, groups
+		if attr.Key != "" {
+			nestedPrefix = prefix + attr.Key + "."
+			nestedGroups = append(groups[:len(groups):len(groups)], attr.Key)
+		}
+		for _, a := range attr.Value.Group() {
+			buf = h.appendAttr(buf, nestedPrefix, nestedGroups, a)
+		}
+		return buf
+	}
+
+	buf = append(buf, ' ')
+	buf = appendLogfmtKey(buf, prefix+attr.Key)
+	buf = append(buf, '=')
+	return appendLogfmtValue(buf, logfmtValue(attr.Value))
+}
+
+// logfmtValue renders a value as an unquoted string
+func logfmtValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339)
+	case slog.KindAny:
+		switch value := v.Any().(type) {
+		case *slog.Source:
+			return value.File + ":" + strconv.Itoa(value.Line)
+		case error:
+			return value.Error()
+		}
+	}
+	return v.String()
+}
+
+// appendLogfmtKey appends the key with characters logfmt can't hold in keys replaced
+func appendLogfmtKey(buf []byte, key string) []byte {
+	if key == "" {
+		return append(buf, '_')
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			r = '_'
+		}
+		buf = append(buf, string(r)...)
+	}
+	return buf
+}
+
+// appendLogfmtValue appends the value, quoting it when it is empty or contains
+// spaces, '=', quotes or non-printable characters
+func appendLogfmtValue(buf []byte, value string) []byte {
+	if value != "" && !strings.ContainsFunc(value, needsLogfmtQuote) {
+		return append(buf, value...)
+	}
+	return strconv.AppendQuote(buf, value)
+}
+
+// needsLogfmtQuote reports whether the rune forces a value to be quoted
+func needsLogfmtQuote(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r)
+}
@@ -0,0 +1,76 @@
+package iSlogger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtHandler_Quoting(t *testing.T) {
+	var buf bytes.Buffer
+	handler := newLogfmtHandler(&buf, nil)
+
+	record := slog.NewRecord(time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC), slog.LevelInfo, "User logged in", 0)
+	record.Add(
+		"plain", "value",
+		"spaces", "hello world",
+		"quotes", `say "hi"`,
+		"empty", "",
+		"equals", "a=b",
+		"ok", true,
+		"failed", false,
+		"count", 3,
+		"err", errors.New("connection refused"),
+	)
+	if err := handler.Handle(context.Background(), record); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	expected := `time=2024-03-09T14:30:00Z level=INFO msg="User logged in" plain=value spaces="hello world" ` +
+		`quotes="say \"hi\"" empty="" equals="a=b" ok=true failed=false count=3 err="connection refused"` + "\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Unexpected logfmt line:\n got: %s\nwant: %s", got, expected)
+	}
+}
+
+func TestLogfmtHandler_GroupsAndKeys(t *testing.T) {
+	var buf bytes.Buffer
+	handler := newLogfmtHandler(&buf, nil).
+		WithAttrs([]slog.Attr{slog.String("service", "api")}).
+		WithGroup("request")
+
+	record := slog.NewRecord(time.Time{}, slog.LevelWarn, "slow", 0)
+	record.Add("bad key", "x", slog.Group("user", "id", 7), "line", "one\ntwo")
+	handler.Handle(context.Background(), record)
+
+	expected := `level=WARN msg=slow service=api request.bad_key=x request.user.id=7 request.line="one\ntwo"` + "\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Unexpected logfmt line:\n got: %s\nwant: %s", got, expected)
+	}
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-logfmt").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithInMemoryTail(1).
+		WithLogfmtFormat(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("Order placed", "note", "")
+
+	line := logger.Tail()[0]
+	if !strings.HasPrefix(line, "time=") || !strings.Contains(line, `msg="Order placed" note=""`) {
+		t.Errorf("Expected a logfmt record, got: %s", line)
+	}
+}
@@ -2,293 +2,893 @@ package iSlogger
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
+	"math"
 	"os"
 	"path/filepath"
-	"strings"
+	"runtime"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// levelFilterWriter filters logs by level
-type levelFilterWriter struct {
-	writer   io.Writer
-	maxLevel slog.Level // Maximum level to write (inclusive)
-}
-
-func (lfw *levelFilterWriter) Write(p []byte) (n int, err error) {
-	logStr := string(p)
+// Logger wraps slog.Logger with file rotation
+type Logger struct {
+	*loggerCore
 
-	if strings.Contains(logStr, "level=WARN") ||
-		strings.Contains(logStr, "level=ERROR") ||
-		strings.Contains(logStr, `"level":"WARN"`) ||
-		strings.Contains(logStr, `"level":"ERROR"`) {
-		// Don't write WARN/ERROR to info file
-		return len(p), nil
-	}
+	// chain holds the With/WithGroup steps applied on top of the core loggers
+	chain []chainStep
+	cache atomic.Pointer[derivedLoggers]
 
-	// Write DEBUG/INFO to info file
-	return lfw.writer.Write(p)
+	suspended atomic.Int32 // Number of active Suspend calls on this instance
 }
 
-// Logger wraps slog.Logger with file rotation
-type Logger struct {
+// loggerCore holds the file and handler state shared by a logger and all loggers derived from it
+type loggerCore struct {
 	config      Config
 	infoLogger  *slog.Logger
 	errorLogger *slog.Logger
-	infoFile    *os.File
-	errorFile   *os.File
-	infoBuffer  *bufferedWriter
-	errorBuffer *bufferedWriter
+	files       []*logFile   // All currently open files
+	tenants     *tenantFiles // Open per-tenant files, nil without TenantField
+	tail        *ringBuffer
+	origin      []slog.Attr // Host and pid attributes, resolved once per configuration
+	limiter     *rateLimiter
+	stats       dropStats
+	children    []*Logger     // Loggers a MultiLogger fans out to
+	async       *asyncQueue   // Background writer queue, nil when writing synchronously
+	level       slog.LevelVar // Shared by all handlers so the level can change without reopening files
+	levelFloor  atomic.Int64  // Records below both level and this floor are dropped before they are built
+	sequence    atomic.Uint64 // Last sequence number assigned, shared by derived loggers
 	currentDate string
+	generation  uint64 // Incremented every time the loggers are rebuilt
+	nop         bool   // Never touch the filesystem (NewNop, NewWithHandler)
 	mu          sync.RWMutex
+	done        chan struct{}
+	closeOnce   sync.Once
+	hooksOnce   sync.Once   // Runs the OnClose hooks on the first Close
+	stopped     atomic.Bool // Set by Shutdown; new records are discarded
+}
+
+// chainStep is a With or WithGroup step applied on top of the core loggers
+type chainStep struct {
+	group string      // Group opened by the step, empty for With steps
+	attrs []slog.Attr // Attributes added by With steps
+}
+
+// apply returns the logger with the step applied
+func (s chainStep) apply(logger *slog.Logger) *slog.Logger {
+	if s.group != "" {
+		return logger.WithGroup(s.group)
+	}
+	// Handlers own the slice they are given, so each one gets a copy
+	return slog.New(logger.Handler().WithAttrs(slices.Clone(s.attrs)))
+}
+
+// derivedLoggers caches the chain applied to a specific core generation
+type derivedLoggers struct {
+	generation  uint64
+	infoLogger  *slog.Logger
+	errorLogger *slog.Logger
 }
 
 // New creates a new Logger instance
 func New(config Config) (*Logger, error) {
 	// Set defaults if empty
-	if config.LogDir == "" {
-		config.LogDir = "logs"
-	}
-	if config.AppName == "" {
-		config.AppName = "app"
-	}
-	if config.RetentionDays <= 0 {
-		config.RetentionDays = 7
-	}
-	if config.TimeFormat == "" {
-		config.TimeFormat = time.RFC3339
+	config = config.withDefaults()
+
+	l, err := openLogger(config)
+	if err != nil {
+		if !config.FallbackToTempDir {
+			return nil, err
+		}
+
+		fallback := config.WithLogDir(config.tempLogDir())
+		var fallbackErr error
+		if l, fallbackErr = openLogger(fallback); fallbackErr != nil {
+			return nil, errors.Join(err, fallbackErr)
+		}
+		l.Warn("Log directory unusable, logging to temporary directory",
+			"log_dir", config.LogDir, "fallback_dir", fallback.LogDir, "error", err)
 	}
+	return l.start(), nil
+}
 
+// LogDir returns the directory the logger writes to, which differs from the
+// configured one after a fallback to the temporary directory
+func (l *Logger) LogDir() string {
+	return l.currentConfig().LogDir
+}
+
+// openLogger creates the log directory and opens the files of a new logger
+func openLogger(config Config) (*Logger, error) {
 	// Create log directory
-	if err := os.MkdirAll(config.LogDir, 0o700); err != nil {
+	if err := config.makeDir(config.LogDir); err != nil {
 		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
 	l := &Logger{
-		config:      config,
-		currentDate: time.Now().Format("2006-01-02"),
+		loggerCore: &loggerCore{
+			config:      config,
+			currentDate: config.now().Format("2006-01-02"),
+			limiter:     newRateLimiter(config.Filters),
+			origin:      config.originAttrs(),
+			done:        make(chan struct{}),
+		},
+	}
+
+	if config.TailCapacity > 0 {
+		l.tail = newRingBuffer(config.TailCapacity)
 	}
 
 	if err := l.initLoggers(); err != nil {
 		return nil, err
 	}
+	return l, nil
+}
 
-	// Start cleanup
+// start launches the background work of a logger opened by openLogger
+func (l *Logger) start() *Logger {
+	config := l.config
+	if config.AsyncQueueSize > 0 {
+		l.async = newAsyncQueue(config.AsyncQueueSize, config.AsyncOverflow, l)
+	}
+
+	// Start cleanup and midnight rotation
 	go l.startCleanupRoutine()
+	go l.startRotationTimer(maxRotationWait)
 
-	return l, nil
+	if config.ReloadPath != "" {
+		go l.startSignalReload(config.ReloadPath)
+	}
+
+	if config.Register {
+		register(l)
+	}
+
+	return l
 }
 
 // initLoggers initializes both info and error loggers
 func (l *Logger) initLoggers() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	return l.initLoggersLocked()
+}
 
-	// Close existing buffers and files if open
-	if l.infoBuffer != nil {
-		l.infoBuffer.Close()
+// initLoggersLocked initializes both loggers (must be called with lock held)
+func (l *Logger) initLoggersLocked() error {
+	if l.nop {
+		return nil
 	}
-	if l.errorBuffer != nil {
-		l.errorBuffer.Close()
+
+	today := l.config.now().Format("2006-01-02")
+
+	// Close the day in the outgoing files before they are closed
+	if l.config.DailySummary && l.infoLogger != nil && l.currentDate != today {
+		l.writeDailySummaryLocked()
 	}
-	if l.infoFile != nil {
-		l.infoFile.Close()
+
+	// Close existing buffers and files if open, archiving them on a date change
+	var previous []string
+	for _, lf := range l.files {
+		previous = append(previous, lf.path)
 	}
-	if l.errorFile != nil {
-		l.errorFile.Close()
+	if l.tenants != nil {
+		previous = append(previous, l.tenants.paths()...)
 	}
-
-	var err error
-	today := time.Now().Format("2006-01-02")
+	l.closeFilesLocked()
+	if l.config.BackupDir != "" && l.currentDate != today {
+		l.archiveFilesLocked(previous)
+	}
+	previousDate := l.currentDate
 
 	baseDir, err := filepath.Abs(l.config.LogDir)
 	if err != nil {
 		return fmt.Errorf("resolve log dir: %w", err)
 	}
 
-	// Open info log file
-	infoPath := filepath.Join(baseDir, fmt.Sprintf("%s_%s.log", l.config.AppName, today))
+	// slog options
+	opts := &slog.HandlerOptions{
+		AddSource: l.config.AddSource,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if l.config.ECSFormat && a.Key == slog.SourceKey && len(groups) == 0 {
+				if source, ok := a.Value.Any().(*slog.Source); ok {
+					return l.config.ecsOrigin(source)
+				}
+			}
+			a = l.replaceAttr(groups, a)
+			// Renamed built-in keys
+			if len(groups) == 0 {
+				a.Key = l.config.builtinKey(a.Key)
+			}
+			return a
+		},
+	}
 
-	if rel, err := filepath.Rel(baseDir, infoPath); err != nil || strings.HasPrefix(rel, "..") {
-		return fmt.Errorf("invalid log file path: %s", infoPath)
+	// Set log level from config
+	l.level.Set(l.config.LogLevel)
+	opts.Level = &l.level
+	l.levelFloor.Store(math.MaxInt64)
+	if floor, ok := l.config.Filters.overrideFloor(); ok {
+		opts.Level = floorLeveler{level: &l.level, floor: floor}
+		l.levelFloor.Store(int64(floor))
+	}
+	if len(l.config.HandlerMiddleware) > 0 {
+		l.levelFloor.Store(math.MinInt64) // Middleware may enable any level
 	}
 
-	l.infoFile, err = os.OpenFile(infoPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	// Create base file handlers
+	var infoHandler, errorHandler slog.Handler
+	switch {
+	case l.config.PerLevelFiles:
+		infoHandler, errorHandler, err = l.perLevelFileHandlers(baseDir, today, opts)
+	case l.config.CombinedFile:
+		infoHandler, err = l.combinedFileHandler(baseDir, today, opts)
+	default:
+		infoHandler, errorHandler, err = l.splitFileHandlers(baseDir, today, opts)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to open info log file: %w", err)
+		l.closeFilesLocked()
+		return err
 	}
 
-	// Open error log file
-	errorPath := filepath.Join(baseDir, fmt.Sprintf("%s_error_%s.log", l.config.AppName, today))
-	if rel, err := filepath.Rel(baseDir, errorPath); err != nil || strings.HasPrefix(rel, "..") {
-		return fmt.Errorf("invalid log_error file path: %s", errorPath)
+	// Records carrying the tenant field go to their tenant's file instead
+	if l.config.TenantField != "" {
+		l.tenants = l.newTenantFiles(baseDir, today, opts)
+		infoHandler = newTenantHandler(infoHandler, l.tenants, l.config.TenantField, true)
+		if errorHandler != nil {
+			errorHandler = newTenantHandler(errorHandler, l.tenants, l.config.TenantField, false)
+		}
 	}
 
-	l.errorFile, err = os.OpenFile(errorPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
-	if err != nil {
-		return fmt.Errorf("failed to open error log file: %w", err)
+	// The info stream sees every level, so the tail is fed from there
+	if l.tail != nil {
+		infoHandler = newMultiHandler(infoHandler, l.config.formatHandler(l.tail, l.config.fileFormat(), opts))
 	}
 
-	// Create buffered writers for file output
-	l.infoBuffer = newBufferedWriter(l.infoFile, l.config.BufferSize, l.config.FlushInterval, l.config.FlushOnLevel)
-	l.errorBuffer = newBufferedWriter(l.errorFile, l.config.BufferSize, l.config.FlushInterval, l.config.FlushOnLevel)
+	// Console gets its own handlers so it can use a different format
+	if l.config.ConsoleOutput {
+		consoleInfo := l.config.consoleHandler(os.Stdout, opts)
+		consoleError := l.config.consoleHandler(os.Stderr, opts)
+		if l.config.StdErrSplit {
+			// Each record goes to exactly one stream, both fed from the info stream that sees every level
+			threshold := l.config.StdErrThreshold
+			infoHandler = newMultiHandler(infoHandler,
+				&belowLevelHandler{handler: consoleInfo, limit: threshold},
+				&minLevelHandler{handler: consoleError, min: threshold})
+		} else {
+			// Warnings and errors reach stderr through the error stream, as well as stdout
+			infoHandler = newMultiHandler(infoHandler, consoleInfo)
+			if errorHandler != nil {
+				errorHandler = newMultiHandler(errorHandler, consoleError)
+			} else {
+				errorHandler = consoleError
+			}
+		}
+	}
 
-	// Create writers based on console output configuration
-	infoFileWriter := &levelFilterWriter{
-		writer:   l.infoBuffer,
-		maxLevel: slog.LevelInfo, // Only DEBUG and INFO
+	// The binary writer gets every level, so it hangs off the info stream
+	if l.config.BinaryWriter != nil {
+		infoHandler = newMultiHandler(infoHandler, newBinaryHandler(l.config.BinaryWriter, l.config.ProtoEncoder, opts.Level))
 	}
 
-	var infoWriter, errorWriter io.Writer
-	if l.config.ConsoleOutput {
-		// Enable console output
-		infoWriter = io.MultiWriter(os.Stdout, infoFileWriter)
-		errorWriter = io.MultiWriter(os.Stderr, l.errorBuffer)
-	} else {
-		// File output only
-		infoWriter = infoFileWriter
-		errorWriter = l.errorBuffer
+	if errorHandler == nil {
+		errorHandler = slog.DiscardHandler
 	}
 
-	// slog options
-	opts := &slog.HandlerOptions{
-		AddSource: l.config.AddSource,
-		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
-			// Custom time format
-			if a.Key == slog.TimeKey {
-				return slog.Attr{
-					Key:   a.Key,
-					Value: slog.StringValue(a.Value.Time().Format(l.config.TimeFormat)),
-				}
-			}
-			return a
-		},
+	// Flatten after filtering, so filters still see the nested groups
+	if l.config.GroupSeparator != "" {
+		infoHandler = newFlattenHandler(infoHandler, l.config.GroupSeparator)
+		errorHandler = newFlattenHandler(errorHandler, l.config.GroupSeparator)
 	}
 
-	// Set log level from config
-	opts.Level = l.config.LogLevel
+	// User middleware sees filtered records; the first one added runs first
+	for _, middleware := range slices.Backward(l.config.HandlerMiddleware) {
+		infoHandler = middleware(infoHandler)
+		errorHandler = middleware(errorHandler)
+	}
 
-	// Create base handlers
-	var infoHandler, errorHandler slog.Handler
-	if l.config.JSONFormat {
-		infoHandler = slog.NewJSONHandler(infoWriter, opts)
-		errorHandler = slog.NewJSONHandler(errorWriter, opts)
-	} else {
-		infoHandler = slog.NewTextHandler(infoWriter, opts)
-		errorHandler = slog.NewTextHandler(errorWriter, opts)
+	// Wrap with filtered handlers. Only the info stream sees every record,
+	// so it alone counts drops to avoid counting WARN and above twice.
+	// Conditions are checked once per record in write, before a sequence number is assigned.
+	handlerFilters := l.config.Filters
+	handlerFilters.Conditions = nil
+	filteredInfoHandler := newFilteredHandler(infoHandler, handlerFilters, &l.stats)
+	filteredErrorHandler := newFilteredHandler(errorHandler, handlerFilters, nil)
+
+	// Origin attributes come before any user attributes
+	var infoRoot, errorRoot slog.Handler = filteredInfoHandler, filteredErrorHandler
+	if len(l.origin) > 0 {
+		infoRoot = infoRoot.WithAttrs(l.origin)
+		errorRoot = errorRoot.WithAttrs(l.origin)
 	}
 
-	// Wrap with filtered handlers
-	filteredInfoHandler := newFilteredHandler(infoHandler, l.config.Filters)
-	filteredErrorHandler := newFilteredHandler(errorHandler, l.config.Filters)
+	l.infoLogger = slog.New(infoRoot)
+	l.errorLogger = slog.New(errorRoot)
+	if len(l.config.GlobalFields) > 0 {
+		l.infoLogger = l.infoLogger.With(l.config.GlobalFields...)
+		l.errorLogger = l.errorLogger.With(l.config.GlobalFields...)
+	}
 
-	l.infoLogger = slog.New(filteredInfoHandler)
-	l.errorLogger = slog.New(filteredErrorHandler)
+	if l.config.CurrentSymlink {
+		l.linkCurrentFilesLocked(today)
+	}
+	if l.config.RotationHook != nil && len(previous) > 0 {
+		l.reportRotationLocked(previous, previousDate, today)
+	}
 
 	l.currentDate = today
+	l.generation++
 	return nil
 }
 
+// writeDailySummaryLocked writes the day's record counts per level to the outgoing
+// info stream and resets them for the new day (must be called with lock held)
+func (l *Logger) writeDailySummaryLocked() {
+	record := slog.NewRecord(l.config.now(), slog.LevelInfo, "daily summary", 0)
+	record.AddAttrs(slog.String("date", l.currentDate))
+	for i, kind := range levelKinds {
+		record.AddAttrs(slog.Uint64(kind, l.stats.levelCounts[i].Load()))
+	}
+	l.infoLogger.Handler().Handle(context.Background(), record)
+
+	// Reset after writing so the summary itself isn't counted. Writers hold the
+	// lock for reading, so no record can be counted in between.
+	for i := range l.stats.levelCounts {
+		l.stats.levelCounts[i].Store(0)
+	}
+}
+
+// replaceAttr applies the configured time format, level names, source format
+// and duration rendering to an attribute about to be written
+func (l *Logger) replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	// Custom time format, only for time values so a user "time" attribute keeps its type
+	if a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+		return slog.Attr{
+			Key:   a.Key,
+			Value: slog.StringValue(a.Value.Time().Format(l.config.TimeFormat)),
+		}
+	}
+	// Custom level names
+	if a.Key == slog.LevelKey && len(groups) == 0 && len(l.config.LevelNames) > 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			if name, exists := l.config.LevelNames[level]; exists {
+				return slog.String(a.Key, name)
+			}
+		}
+	}
+	// Shortened source locations
+	if a.Key == slog.SourceKey && len(groups) == 0 {
+		if source, ok := a.Value.Any().(*slog.Source); ok {
+			if formatted, ok := l.config.SourceFormat.format(source); ok {
+				return slog.String(a.Key, formatted)
+			}
+		}
+	}
+	// Consistent numeric durations
+	if l.config.DurationAsMillis && a.Value.Kind() == slog.KindDuration {
+		return slog.Int64(a.Key, a.Value.Duration().Milliseconds())
+	}
+	return a
+}
+
+// splitFileHandlers opens the info and error files. The info file only keeps
+// DEBUG and INFO, while the error file receives WARN and above.
+func (l *Logger) splitFileHandlers(baseDir, date string, opts *slog.HandlerOptions) (infoHandler, errorHandler slog.Handler, err error) {
+	infoFile, err := l.openLogFile(baseDir, l.config.logFileName("", date))
+	if err != nil {
+		return nil, nil, err
+	}
+	errorFile, err := l.openLogFile(baseDir, l.config.logFileName("error", date))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The info file only keeps levels below WARN, custom ones included
+	infoFileHandler := &belowLevelHandler{
+		handler: l.config.fileHandler(infoFile, opts),
+		limit:   slog.LevelWarn,
+	}
+	return infoFileHandler, l.config.fileHandler(errorFile, opts), nil
+}
+
+// combinedFileHandler opens the single file that receives every level
+func (l *Logger) combinedFileHandler(baseDir, date string, opts *slog.HandlerOptions) (slog.Handler, error) {
+	file, err := l.openLogFile(baseDir, l.config.logFileName("", date))
+	if err != nil {
+		return nil, err
+	}
+	return l.config.fileHandler(file, opts), nil
+}
+
+// perLevelFileHandlers opens one file per level. The info stream routes every record
+// to its level's file; the error stream only duplicates WARN into the error file if enabled.
+func (l *Logger) perLevelFileHandlers(baseDir, date string, opts *slog.HandlerOptions) (infoHandler, errorHandler slog.Handler, err error) {
+	var handlers [len(levelKinds)]slog.Handler
+	for i, kind := range levelKinds {
+		lf, err := l.openLogFile(baseDir, l.config.logFileName(kind, date))
+		if err != nil {
+			return nil, nil, err
+		}
+		handlers[i] = l.config.fileHandler(lf, opts)
+	}
+
+	infoHandler = newLevelRouterHandler(handlers)
+	if l.config.PerLevelDuplicate {
+		var duplicates [len(levelKinds)]slog.Handler
+		duplicates[levelIndex(slog.LevelWarn)] = handlers[levelIndex(slog.LevelError)]
+		errorHandler = newLevelRouterHandler(duplicates)
+	}
+	return infoHandler, errorHandler, nil
+}
+
+// closeFilesLocked flushes and closes all open files (must be called with lock held)
+func (l *Logger) closeFilesLocked() error {
+	var errs []error
+	for _, lf := range l.files {
+		if err := lf.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	l.files = nil
+	if l.tenants != nil {
+		if err := l.tenants.close(); err != nil {
+			errs = append(errs, err)
+		}
+		l.tenants = nil
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("errors closing logger: %v", errs)
+	}
+	return nil
+}
+
+// now returns the current time from the configured clock, for record times
+func (c *loggerCore) now() time.Time {
+	c.mu.RLock()
+	clock := c.config.Clock
+	c.mu.RUnlock()
+	if clock != nil {
+		return clock()
+	}
+	return nowFunc()
+}
+
+// currentConfig returns a snapshot of the active configuration
+func (c *loggerCore) currentConfig() Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config
+}
+
 // checkDateRotation checks if we need to rotate log files
 func (l *Logger) checkDateRotation() {
-	today := time.Now().Format("2006-01-02")
-	if today != l.currentDate {
-		l.initLoggers() // This will handle the rotation
+	if l.nop {
+		return
+	}
+
+	l.mu.RLock()
+	today := l.config.now().Format("2006-01-02")
+	current := l.currentDate
+	l.mu.RUnlock()
+
+	if today != current {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		// Another goroutine may have rotated already
+		if today != l.currentDate {
+			l.initLoggersLocked() // This will handle the rotation
+		}
 	}
 }
 
-// Debug logs debug level message
-func (l *Logger) Debug(msg string, args ...any) {
-	l.checkDateRotation()
+// loggers returns the slog loggers with this logger's chain applied
+// to the current core loggers (must be called with lock held)
+func (l *Logger) loggers() (infoLogger, errorLogger *slog.Logger) {
+	if len(l.chain) == 0 {
+		return l.infoLogger, l.errorLogger
+	}
+
+	if cached := l.cache.Load(); cached != nil && cached.generation == l.generation {
+		return cached.infoLogger, cached.errorLogger
+	}
+
+	infoLogger, errorLogger = l.infoLogger, l.errorLogger
+	for _, step := range l.chain {
+		infoLogger = step.apply(infoLogger)
+		errorLogger = step.apply(errorLogger)
+	}
+	l.cache.Store(&derivedLoggers{
+		generation:  l.generation,
+		infoLogger:  infoLogger,
+		errorLogger: errorLogger,
+	})
+	return infoLogger, errorLogger
+}
+
+// log writes a record at the given level. It must be called directly
+// from an exported logging method so the caller frame can be located.
+func (l *Logger) log(level slog.Level, msg string, args ...any) {
+	if !l.levelEnabled(level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip [runtime.Callers, log, exported method]
+	l.logPC(pcs[0], level, msg, args...)
+}
+
+// levelEnabled is a lock-free check of whether a record at level may pass the
+// logger's level, so disabled levels cost almost nothing. Loggers without files
+// leave the decision to their handler.
+func (l *Logger) levelEnabled(level slog.Level) bool {
+	return l.nop || level >= l.level.Level() || int64(level) >= l.levelFloor.Load()
+}
+
+// logPC writes a record attributed to the caller at pc
+func (l *Logger) logPC(pc uintptr, level slog.Level, msg string, args ...any) {
+	record := slog.NewRecord(l.now(), level, msg, pc)
+	record.Add(args...)
+	l.handle(context.Background(), record)
+}
+
+// handle routes a record to the info handler and, for WARN and above, to the error handler too
+func (l *Logger) handle(ctx context.Context, record slog.Record) error {
+	if l.suspended.Load() > 0 || l.stopped.Load() {
+		return nil
+	}
+
+	// Don't spend time on records for abandoned requests; errors are still worth keeping
+	if ctx != nil && ctx.Err() != nil && record.Level < slog.LevelError && l.currentConfig().RespectContextCancellation {
+		l.stats.conditionDropped.Add(1)
+		return nil
+	}
+
+	// In async mode the background writer does the rest, unless the queue is closed
+	if l.async != nil {
+		if !l.enabled(ctx, record.Level) {
+			return nil
+		}
+		// The caller's stack only exists on this goroutine, so capture it before queueing
+		l.mu.RLock()
+		record = l.addStacksLocked(record)
+		l.mu.RUnlock()
+		if l.async.enqueue(asyncRecord{logger: l, ctx: ctx, record: record.Clone()}) {
+			return nil
+		}
+	}
+	return l.write(ctx, record)
+}
+
+// enabled reports whether the logger's handlers accept records at the level
+func (l *Logger) enabled(ctx context.Context, level slog.Level) bool {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
-	l.infoLogger.Debug(msg, args...)
+
+	infoLogger, _ := l.loggers()
+	return infoLogger.Handler().Enabled(ctx, level)
 }
 
-// Info logs info level message
-func (l *Logger) Info(msg string, args ...any) {
+// write filters the record and writes it to the streams on the calling goroutine
+func (l *Logger) write(ctx context.Context, record slog.Record) error {
 	l.checkDateRotation()
 	l.mu.RLock()
 	defer l.mu.RUnlock()
-	l.infoLogger.Info(msg, args...)
+
+	infoLogger, errorLogger := l.loggers()
+	if !infoLogger.Handler().Enabled(ctx, record.Level) {
+		return nil
+	}
+
+	// Records below the logger's level only pass through a matching level override
+	if len(l.config.Filters.LevelOverrides) > 0 && record.Level < l.level.Level() &&
+		!l.config.Filters.levelOverridden(record) {
+		return nil
+	}
+
+	// The caller is known from the record PC, which is captured even without AddSource
+	if l.config.Filters.hasPackageFilter() && !l.config.Filters.packageAllowed(record.PC) {
+		l.stats.conditionDropped.Add(1)
+		return nil
+	}
+
+	// Rate limits are checked once per record, before it is fanned out to the streams
+	if !l.limiter.allow(record.Level, record.Message) {
+		l.stats.rateLimited.Add(1)
+		return nil
+	}
+
+	if !l.config.Filters.recordPasses(record) {
+		l.stats.conditionDropped.Add(1)
+		return nil
+	}
+
+	// Numbered only once every filter passed it, so gaps mean records were lost downstream
+	if l.config.SequenceNumbers {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx = context.WithValue(ctx, sequenceContextKey{}, l.sequence.Add(1))
+	}
+
+	record = l.addStacksLocked(record)
+
+	if err := infoLogger.Handler().Handle(ctx, record.Clone()); err != nil {
+		return err
+	}
+	if record.Level >= slog.LevelWarn {
+		if err := errorLogger.Handler().Handle(ctx, record); err != nil {
+			return err
+		}
+	}
+	if l.config.SyncOnError && record.Level >= slog.LevelError {
+		return l.syncLocked()
+	}
+	return nil
+}
+
+// Debug logs debug level message
+func (l *Logger) Debug(msg string, args ...any) {
+	l.log(slog.LevelDebug, msg, args...)
+}
+
+// Info logs info level message
+func (l *Logger) Info(msg string, args ...any) {
+	l.log(slog.LevelInfo, msg, args...)
 }
 
 // Warn logs warning level message
 func (l *Logger) Warn(msg string, args ...any) {
-	l.checkDateRotation()
-	l.mu.RLock()
-	defer l.mu.RUnlock()
-	l.infoLogger.Warn(msg, args...)
-	l.errorLogger.Warn(msg, args...)
+	l.log(slog.LevelWarn, msg, args...)
 }
 
 // Error logs error level message
 func (l *Logger) Error(msg string, args ...any) {
-	l.checkDateRotation()
-	l.mu.RLock()
-	defer l.mu.RUnlock()
-	l.infoLogger.Error(msg, args...)
-	l.errorLogger.Error(msg, args...)
+	l.log(slog.LevelError, msg, args...)
+}
+
+// exitFunc ends the process after Fatal; tests replace it to cover the exit path
+var exitFunc = os.Exit
+
+// Fatal logs an error message, syncs every file so it survives, and exits the
+// process with FatalExitCode (1 by default). Deferred functions do not run.
+func (l *Logger) Fatal(msg string, args ...any) {
+	l.log(slog.LevelError, msg, args...)
+	l.Sync()
+	exitFunc(l.currentConfig().fatalExitCode())
+}
+
+// fatalExitCode returns the exit code used by Fatal
+func (c Config) fatalExitCode() int {
+	if c.FatalExitCode == 0 {
+		return 1
+	}
+	return c.FatalExitCode
+}
+
+// Log logs a message at any level, including custom levels between or beyond the standard ones
+func (l *Logger) Log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if !l.levelEnabled(level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:]) // skip [runtime.Callers, Log]
+	record := slog.NewRecord(l.now(), level, msg, pcs[0])
+	record.Add(args...)
+	l.handle(ctx, record)
 }
 
 // With creates a logger with additional attributes
 func (l *Logger) With(args ...any) *Logger {
-	l.mu.RLock()
-	defer l.mu.RUnlock()
+	attrs := argsToAttrs(args)
+	if l.currentConfig().DedupAttrs {
+		return l.withDeduped(attrs)
+	}
+	return l.derive(chainStep{attrs: attrs})
+}
+
+// withDeduped creates a logger with the attributes, dropping earlier values of
+// the same keys. Only With steps after the last open group share their level.
+func (l *Logger) withDeduped(attrs []slog.Attr) *Logger {
+	attrs = lastByKey(attrs)
+	replaced := func(a slog.Attr) bool {
+		return slices.ContainsFunc(attrs, func(b slog.Attr) bool { return a.Key == b.Key })
+	}
+
+	start := len(l.chain)
+	for start > 0 && l.chain[start-1].group == "" {
+		start--
+	}
+
+	chain := make([]chainStep, start, len(l.chain)+1)
+	copy(chain, l.chain[:start])
+	for _, step := range l.chain[start:] {
+		if kept := slices.DeleteFunc(slices.Clone(step.attrs), replaced); len(kept) > 0 {
+			chain = append(chain, chainStep{attrs: kept})
+		}
+	}
 
-	newLogger := &Logger{
-		config:      l.config,
-		infoFile:    l.infoFile,
-		errorFile:   l.errorFile,
-		infoBuffer:  l.infoBuffer,
-		errorBuffer: l.errorBuffer,
-		currentDate: l.currentDate,
-		infoLogger:  l.infoLogger.With(args...),
-		errorLogger: l.errorLogger.With(args...),
+	return &Logger{
+		loggerCore: l.loggerCore,
+		chain:      append(chain, chainStep{attrs: attrs}),
 	}
-	return newLogger
 }
 
-// WithContext creates a logger with context
+// lastByKey keeps only the last attribute for each key
+func lastByKey(attrs []slog.Attr) []slog.Attr {
+	kept := make([]slog.Attr, 0, len(attrs))
+	for i, a := range attrs {
+		if !slices.ContainsFunc(attrs[i+1:], func(b slog.Attr) bool { return a.Key == b.Key }) {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// WithContext creates a logger with context. With AutoCorrelationID it also binds
+// the correlation ID carried by ctx. A context without one binds none, as an ID
+// generated here could not reach the caller's context: add it where the request
+// enters with ContextWithCorrelationID or WithCorrelationID.
 func (l *Logger) WithContext(ctx context.Context) *Logger {
-	l.mu.RLock()
-	defer l.mu.RUnlock()
+	if l.currentConfig().AutoCorrelationID {
+		if id, ok := CorrelationIDFromContext(ctx); ok {
+			l = l.With(CorrelationIDKey, id)
+		}
+	}
+	return l.derive(chainStep{group: "context"})
+}
 
-	newLogger := &Logger{
-		config:      l.config,
-		infoFile:    l.infoFile,
-		errorFile:   l.errorFile,
-		infoBuffer:  l.infoBuffer,
-		errorBuffer: l.errorBuffer,
-		currentDate: l.currentDate,
-		infoLogger:  l.infoLogger.WithGroup("context"),
-		errorLogger: l.errorLogger.WithGroup("context"),
+// derive creates a logger sharing this logger's core with an extra step in the chain.
+// The chain is re-applied whenever the core rebuilds its loggers, so derived loggers
+// follow rotation and reconfiguration.
+func (l *Logger) derive(step chainStep) *Logger {
+	chain := make([]chainStep, len(l.chain), len(l.chain)+1)
+	copy(chain, l.chain)
+
+	return &Logger{
+		loggerCore: l.loggerCore,
+		chain:      append(chain, step),
+	}
+}
+
+// BoundAttrs returns the attributes bound to this logger with With, nested under
+// the groups opened after it. Global, host and pid fields are not included.
+func (l *Logger) BoundAttrs() []slog.Attr {
+	return boundAttrs(l.chain)
+}
+
+// boundAttrs collects the attributes of the steps, nesting everything after a group step
+func boundAttrs(steps []chainStep) []slog.Attr {
+	var attrs []slog.Attr
+	for i, step := range steps {
+		if step.group != "" {
+			if nested := boundAttrs(steps[i+1:]); len(nested) > 0 {
+				attrs = append(attrs, slog.Attr{Key: step.group, Value: slog.GroupValue(nested...)})
+			}
+			return attrs
+		}
+		attrs = append(attrs, step.attrs...)
 	}
-	return newLogger
+	return attrs
+}
+
+// argsToAttrs converts alternating key-value pairs and Attrs the way slog.Logger.With does
+func argsToAttrs(args []any) []slog.Attr {
+	var record slog.Record
+	record.Add(args...)
+
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
 }
 
-// SetLevel changes the log level dynamically
+// Suspend mutes this logger instance until the returned resume function is called.
+// Other loggers sharing its files, including ones derived with With, keep logging.
+// Calls nest: the logger resumes once every resume function has been called.
+func (l *Logger) Suspend() (resume func()) {
+	l.suspended.Add(1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.suspended.Add(-1)
+		})
+	}
+}
+
+// SetLevel changes the log level dynamically without reopening files
 func (l *Logger) SetLevel(level slog.Level) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	l.config.LogLevel = level
-	return l.initLoggers()
+	l.level.Set(level)
+	return nil
+}
+
+// Config returns the configuration in effect, with defaults applied and any
+// fallback directory in place. Changing the copy has no effect until it is passed
+// to Reconfigure.
+func (l *Logger) Config() Config {
+	return l.currentConfig().clone()
+}
+
+// Reconfigure applies a new configuration at runtime.
+// Buffered data is flushed before the files are reopened with the new settings.
+// Start from Config to change single settings, e.g. l.Reconfigure(l.Config().WithMaxFiles(10)).
+func (l *Logger) Reconfigure(config Config) error {
+	if l.nop {
+		return nil
+	}
+
+	config = config.withDefaults()
+
+	if err := config.makeDir(config.LogDir); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	// Queued records are written with the configuration they were logged under
+	if l.async != nil {
+		l.async.drain()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.flushLocked(); err != nil {
+		return fmt.Errorf("failed to flush before reconfigure: %w", err)
+	}
+
+	oldConfig, oldTail, oldLimiter, oldOrigin := l.config, l.tail, l.limiter, l.origin
+	l.config = config
+	l.limiter = newRateLimiter(config.Filters)
+	l.origin = config.originAttrs()
+	if config.TailCapacity != oldConfig.TailCapacity {
+		l.tail = nil
+		if config.TailCapacity > 0 {
+			l.tail = newRingBuffer(config.TailCapacity)
+		}
+	}
+
+	if err := l.initLoggersLocked(); err != nil {
+		// Fall back to the previous configuration
+		l.config, l.tail, l.limiter, l.origin = oldConfig, oldTail, oldLimiter, oldOrigin
+		if restoreErr := l.initLoggersLocked(); restoreErr != nil {
+			return fmt.Errorf("failed to reconfigure: %w (restore failed: %v)", err, restoreErr)
+		}
+		return fmt.Errorf("failed to reconfigure: %w", err)
+	}
+
+	return nil
 }
 
 // Flush flushes all buffers to ensure data is written to disk
 func (l *Logger) Flush() error {
+	if l.async != nil {
+		l.async.drain()
+	}
+
 	l.mu.RLock()
 	defer l.mu.RUnlock()
+	return errors.Join(l.flushLocked(), l.forEachChild((*Logger).Flush))
+}
 
+// flushLocked flushes all buffers (must be called with lock held)
+func (l *Logger) flushLocked() error {
 	var errs []error
-	if l.infoBuffer != nil {
-		if err := l.infoBuffer.Flush(); err != nil {
+	for _, lf := range l.files {
+		if err := lf.Flush(); err != nil {
 			errs = append(errs, err)
 		}
 	}
-	if l.errorBuffer != nil {
-		if err := l.errorBuffer.Flush(); err != nil {
+	if l.tenants != nil {
+		if err := l.tenants.each((*logFile).Flush); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -299,39 +899,83 @@ func (l *Logger) Flush() error {
 	return nil
 }
 
-// Close closes the logger and its files
-func (l *Logger) Close() error {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+// Sync flushes all buffers and fsyncs the open files so records survive a crash
+func (l *Logger) Sync() error {
+	if l.async != nil {
+		l.async.drain()
+	}
 
-	var errs []error
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return errors.Join(l.syncLocked(), l.forEachChild((*Logger).Sync))
+}
 
-	// Flush and close buffers first
-	if l.infoBuffer != nil {
-		if err := l.infoBuffer.Close(); err != nil {
+// syncLocked flushes and fsyncs all files (must be called with lock held)
+func (l *Logger) syncLocked() error {
+	var errs []error
+	for _, lf := range l.files {
+		if err := lf.Sync(); err != nil {
 			errs = append(errs, err)
 		}
 	}
-	if l.errorBuffer != nil {
-		if err := l.errorBuffer.Close(); err != nil {
+	if l.tenants != nil {
+		if err := l.tenants.each((*logFile).Sync); err != nil {
 			errs = append(errs, err)
 		}
 	}
 
-	// Then close files
-	if l.infoFile != nil {
-		if err := l.infoFile.Close(); err != nil {
-			errs = append(errs, err)
-		}
+	if len(errs) > 0 {
+		return fmt.Errorf("errors syncing files: %v", errs)
 	}
-	if l.errorFile != nil {
-		if err := l.errorFile.Close(); err != nil {
-			errs = append(errs, err)
-		}
+	return nil
+}
+
+// Shutdown stops accepting new records, writes everything queued or buffered and
+// closes the files, like http.Server.Shutdown. If ctx expires first, Shutdown returns
+// the context's error and the logger finishes closing in the background.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	l.stopped.Store(true)
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- l.Close()
+	}()
+
+	select {
+	case err := <-closed:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("errors closing logger: %v", errs)
+// Close closes the logger and its files
+func (l *Logger) Close() error {
+	unregister(l)
+
+	// Write everything still queued before the files are closed
+	if l.async != nil {
+		l.async.close()
 	}
-	return nil
+
+	// Stop background goroutines
+	l.closeOnce.Do(func() {
+		if l.done != nil {
+			close(l.done)
+		}
+	})
+
+	// Flush buffers and close files
+	l.mu.Lock()
+	err := errors.Join(l.closeFilesLocked(), l.forEachChild((*Logger).Close))
+	hooks := l.config.OnClose
+	l.mu.Unlock()
+
+	// Hooks run once, newest first, without the lock so they can't deadlock by logging
+	l.hooksOnce.Do(func() {
+		for _, hook := range slices.Backward(hooks) {
+			err = errors.Join(err, hook())
+		}
+	})
+	return err
 }
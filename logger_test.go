@@ -1,10 +1,18 @@
 package iSlogger
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -31,104 +39,1246 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestLoggerConfig(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := New(Config{LogDir: dir, Filters: FilterConfig{RateLimits: map[slog.Level]RateLimit{}}})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	config := logger.Config()
+	if config.RetentionDays != 7 || config.AppName != "app" || config.TimeFormat != time.RFC3339 ||
+		config.CleanupInterval != 24*time.Hour || config.LogDir != dir {
+		t.Errorf("Expected the defaults to be applied, got %+v", config)
+	}
+
+	// Building on the copy leaves the logger alone until Reconfigure
+	changed := config.WithAppName("renamed").WithRateLimit(slog.LevelInfo, 5, time.Second)
+	if current := logger.Config(); current.AppName != "app" || len(current.Filters.RateLimits) != 0 {
+		t.Errorf("Expected the logger's configuration to be unchanged, got %+v", current)
+	}
+	if err := logger.Reconfigure(changed); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if current := logger.Config(); current.AppName != "renamed" || current.Filters.RateLimits[slog.LevelInfo].MaxCount != 5 {
+		t.Errorf("Expected the reconfigured settings, got %+v", current)
+	}
+}
+
+func TestFallbackToTempDir(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	// A directory below a regular file can't be created, even by root
+	blocker := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	config := DefaultConfig().
+		WithAppName("test-fallback").
+		WithLogDir(filepath.Join(blocker, "logs")).
+		WithConsoleOutput(false)
+
+	if _, err := New(config); err == nil {
+		t.Fatal("Expected New to fail without the fallback")
+	}
+
+	logger, err := New(config.WithFallbackToTempDir(true))
+	if err != nil {
+		t.Fatalf("Expected the fallback to succeed, got: %v", err)
+	}
+	defer logger.Close()
+
+	expected := filepath.Join(tmp, "test-fallback-logs")
+	if logger.LogDir() != expected {
+		t.Errorf("Expected log dir %s, got %s", expected, logger.LogDir())
+	}
+
+	logger.Flush()
+	content, err := os.ReadFile(filepath.Join(expected, "test-fallback_error_"+time.Now().Format("2006-01-02")+".log"))
+	if err != nil {
+		t.Fatalf("Failed to read fallback error file: %v", err)
+	}
+	if !strings.Contains(string(content), "Log directory unusable") || !strings.Contains(string(content), "not-a-dir") {
+		t.Errorf("Expected a warning naming the configured directory, got: %s", content)
+	}
+}
+
 func TestLogLevels(t *testing.T) {
 	config := DefaultConfig().
-		WithAppName("test-levels").
-		WithLogDir("test-logs-levels").
-		WithLogLevel(slog.LevelDebug)
+		WithAppName("test-levels").
+		WithLogDir("test-logs-levels").
+		WithLogLevel(slog.LevelDebug)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-levels")
+
+	logger.Debug("Debug message", "key", "value")
+	logger.Info("Info message", "key", "value")
+	logger.Warn("Warning message", "key", "value")
+	logger.Error("Error message", "key", "value")
+
+	today := time.Now().Format("2006-01-02")
+	infoPath := filepath.Join("test-logs-levels", "test-levels_"+today+".log")
+	errorPath := filepath.Join("test-logs-levels", "test-levels_error_"+today+".log")
+
+	if _, err := os.Stat(infoPath); os.IsNotExist(err) {
+		t.Error("Info log file was not created")
+	}
+
+	if _, err := os.Stat(errorPath); os.IsNotExist(err) {
+		t.Error("Error log file was not created")
+	}
+}
+
+func TestLevelNames(t *testing.T) {
+	const (
+		levelTrace    = slog.Level(-8)
+		levelCritical = slog.Level(12)
+	)
+
+	config := DefaultConfig().
+		WithAppName("test-level-names").
+		WithLogDir("test-logs-level-names").
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithLogLevel(levelTrace).
+		WithLevelNames(map[slog.Level]string{
+			levelTrace:    "TRACE",
+			levelCritical: "CRITICAL",
+		})
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-level-names")
+
+	ctx := context.Background()
+	logger.Log(ctx, levelTrace, "Trace message")
+	logger.Log(ctx, levelCritical, "Critical message")
+	logger.Info("Info message")
+
+	infoPath, errorPath := logger.GetCurrentLogPaths()
+	infoContent, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	errorContent, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+
+	if !strings.Contains(string(infoContent), `level=TRACE msg="Trace message"`) {
+		t.Errorf("Expected TRACE record in info file, got: %s", infoContent)
+	}
+	if !strings.Contains(string(infoContent), "level=INFO") {
+		t.Errorf("Expected standard levels to keep their names, got: %s", infoContent)
+	}
+	if !strings.Contains(string(errorContent), `level=CRITICAL msg="Critical message"`) {
+		t.Errorf("Expected CRITICAL record in error file, got: %s", errorContent)
+	}
+	if strings.Contains(string(infoContent), "Critical message") {
+		t.Errorf("Expected CRITICAL record to stay out of the info file, got: %s", infoContent)
+	}
+}
+
+func TestLevelNamesFlushOnLevel(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-level-names-flush").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithBufferSize(64 * 1024).
+		WithFlushInterval(time.Hour).
+		WithFlushOnLevel(slog.LevelError).
+		WithLevelNames(map[slog.Level]string{12: "CRITICAL"})
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Log(context.Background(), 12, "crit")
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if !strings.Contains(string(content), "level=CRITICAL") {
+		t.Errorf("Expected the renamed level to flush immediately, got: %q", content)
+	}
+}
+
+func TestRespectContextCancellation(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-ctx-cancel").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithRespectContextCancellation(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	logger.Log(cancelled, slog.LevelInfo, "Skipped info")
+	logger.Slog().WarnContext(expired, "Skipped warn")
+	logger.Log(cancelled, slog.LevelError, "Kept error")
+	logger.Log(context.Background(), slog.LevelInfo, "Kept info")
+
+	got := strings.Join(tailMessages(logger), ",")
+	if got != `"Kept error","Kept info"` {
+		t.Errorf("Expected only the error and the live context record, got %s", got)
+	}
+	if dropped := logger.Stats().ConditionDropped; dropped != 2 {
+		t.Errorf("Expected 2 skipped records, got %d", dropped)
+	}
+}
+
+func TestAutoCorrelationID(t *testing.T) {
+	generated := 0
+	config := DefaultConfig().
+		WithAppName("test-correlation").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithAutoCorrelationID(true).
+		WithTraceIDGenerator(func() string {
+			generated++
+			return fmt.Sprintf("gen-%d", generated)
+		})
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	boundID := func(l *Logger) any {
+		t.Helper()
+		attrs := l.BoundAttrs()
+		if len(attrs) == 0 || attrs[0].Key != CorrelationIDKey {
+			t.Fatalf("Expected a bound correlation ID, got %v", attrs)
+		}
+		return attrs[0].Value.Any()
+	}
+
+	// An ID already in the context is reused
+	ctx := ContextWithCorrelationID(context.Background())
+	id, ok := CorrelationIDFromContext(ctx)
+	if !ok || len(id) != 32 {
+		t.Fatalf("Expected a generated 32 digit ID, got %q", id)
+	}
+	if ContextWithCorrelationID(ctx) != ctx {
+		t.Error("Expected a context with an ID to be returned unchanged")
+	}
+	if got := boundID(logger.WithContext(ctx)); got != id {
+		t.Errorf("Expected WithContext to reuse %q, got %v", id, got)
+	}
+	derived, derivedCtx := logger.WithCorrelationID(ctx)
+	if got := boundID(derived); got != id || derivedCtx != ctx {
+		t.Errorf("Expected WithCorrelationID to reuse %q, got %v", id, got)
+	}
+
+	// A missing ID is generated and returned in the context
+	derived, derivedCtx = logger.WithCorrelationID(context.Background())
+	if got, _ := CorrelationIDFromContext(derivedCtx); got != "gen-1" || boundID(derived) != "gen-1" {
+		t.Errorf("Expected the generated ID in both the logger and the context, got %q", got)
+	}
+	ctx = logger.ContextWithCorrelationID(context.Background())
+	if got, _ := CorrelationIDFromContext(ctx); got != "gen-2" {
+		t.Errorf("Expected ContextWithCorrelationID to use the configured generator, got %q", got)
+	}
+
+	// WithContext can't pass a generated ID on, so a context without one binds none
+	// and every logger of the request agrees
+	ctx = context.Background()
+	first, second := logger.WithContext(ctx), logger.WithContext(ctx)
+	if len(first.BoundAttrs()) != 0 || len(second.BoundAttrs()) != 0 {
+		t.Errorf("Expected no correlation ID without one in the context, got %v and %v",
+			first.BoundAttrs(), second.BoundAttrs())
+	}
+	if generated != 2 {
+		t.Errorf("Expected WithContext not to generate IDs, generated %d", generated)
+	}
+
+	// Without the option WithContext binds nothing
+	plain, err := New(DefaultConfig().WithAppName("test-correlation-off").WithLogDir(t.TempDir()).WithConsoleOutput(false))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer plain.Close()
+	if attrs := plain.WithContext(ctx).BoundAttrs(); len(attrs) != 0 {
+		t.Errorf("Expected no bound attributes, got %v", attrs)
+	}
+}
+
+func TestSequenceNumbers(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-seq").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithInMemoryTail(10).
+		WithMaxAttrs(1).
+		WithCondition(func(level slog.Level, msg string, attrs []slog.Attr) bool {
+			return msg != "filtered"
+		}).
+		WithSequenceNumbers(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("first", "a", 1, "b", 2)
+	logger.Debug("below level")
+	logger.Info("filtered")
+	logger.With("user", "alice").Warn("second") // Derived loggers share the counter
+	logger.Info("third")
+
+	expected := []string{
+		`first a=1 attrs_truncated=1 seq=1`,
+		`second user=alice seq=2`,
+		`third seq=3`,
+	}
+	tail := tailMessages(logger)
+	if len(tail) != len(expected) {
+		t.Fatalf("Expected %d records, got %v", len(expected), tail)
+	}
+	for i := range expected {
+		if tail[i] != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], tail[i])
+		}
+	}
+
+	// The error stream carries the same number as the info stream
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if !strings.Contains(string(content), "msg=second user=alice seq=2") {
+		t.Errorf("Expected the warning numbered 2 in the error file, got: %s", content)
+	}
+}
+
+func TestLogLevelChange(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-level").
+		WithLogDir("test-logs-level").
+		WithLogLevel(slog.LevelWarn) // Start with WARN level
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-level")
+
+	if logger.config.LogLevel != slog.LevelWarn {
+		t.Error("Expected log level to be WARN initially")
+	}
+
+	err = logger.SetLevel(slog.LevelDebug)
+	if err != nil {
+		t.Errorf("Failed to change log level: %v", err)
+	}
+
+	if logger.config.LogLevel != slog.LevelDebug {
+		t.Error("Expected log level to be DEBUG after SetLevel(DEBUG)")
+	}
+}
+
+func TestSetLevel_KeepsFilesOpen(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-level-toggle").
+		WithLogDir("test-logs-level-toggle").
+		WithConsoleOutput(false).
+		WithInMemoryTail(10)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-level-toggle")
+
+	files := append([]*logFile(nil), logger.files...)
+
+	for i := range 100 {
+		level := slog.LevelDebug
+		if i%2 == 0 {
+			level = slog.LevelWarn
+		}
+		if err := logger.SetLevel(level); err != nil {
+			t.Fatalf("Failed to change log level: %v", err)
+		}
+	}
+
+	if len(logger.files) != len(files) {
+		t.Fatalf("Expected %d open files, got %d", len(files), len(logger.files))
+	}
+	for i, lf := range logger.files {
+		if lf != files[i] {
+			t.Errorf("Expected file %s to stay open across level changes", lf.path)
+		}
+	}
+
+	logger.Debug("Visible debug")
+	logger.SetLevel(slog.LevelInfo)
+	logger.Debug("Hidden debug")
+
+	lines := logger.Tail()
+	if len(lines) != 1 || !strings.Contains(lines[0], "Visible debug") {
+		t.Errorf("Expected only the debug record logged at DEBUG level, got: %v", lines)
+	}
+}
+
+func TestClock_MidnightRotation(t *testing.T) {
+	var now atomic.Int64
+	now.Store(time.Date(2024, 3, 9, 23, 59, 59, 0, time.Local).UnixNano())
+
+	config := DefaultConfig().
+		WithAppName("test-clock").
+		WithLogDir("test-logs-clock").
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithClock(func() time.Time { return time.Unix(0, now.Load()) })
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-clock")
+
+	logger.Info("Before midnight")
+	beforePath, _ := logger.GetCurrentLogPaths()
+	if filepath.Base(beforePath) != "test-clock_2024-03-09.log" {
+		t.Fatalf("Unexpected file before midnight: %s", beforePath)
+	}
+
+	now.Add(int64(2 * time.Second))
+	logger.Info("After midnight")
+	afterPath, _ := logger.GetCurrentLogPaths()
+	if filepath.Base(afterPath) != "test-clock_2024-03-10.log" {
+		t.Fatalf("Unexpected file after midnight: %s", afterPath)
+	}
+
+	before, err := os.ReadFile(beforePath)
+	if err != nil {
+		t.Fatalf("Failed to read file before midnight: %v", err)
+	}
+	after, err := os.ReadFile(afterPath)
+	if err != nil {
+		t.Fatalf("Expected a new file after midnight: %v", err)
+	}
+	if !strings.Contains(string(before), "Before midnight") || strings.Contains(string(before), "After midnight") {
+		t.Errorf("Unexpected content before midnight: %s", before)
+	}
+	if !strings.Contains(string(after), "After midnight") {
+		t.Errorf("Unexpected content after midnight: %s", after)
+	}
+
+	// Record times come from the clock too, matching the file dates
+	if !strings.Contains(string(before), "2024-03-09") || !strings.Contains(string(after), "2024-03-10") {
+		t.Errorf("Expected record times from the clock, got: %s and %s", before, after)
+	}
+}
+
+func TestClock_RotationWithoutLogging(t *testing.T) {
+	originalWait := maxRotationWait
+	maxRotationWait = 10 * time.Millisecond
+	defer func() { maxRotationWait = originalWait }()
+
+	var now atomic.Int64
+	now.Store(time.Date(2024, 3, 9, 23, 59, 59, 0, time.Local).UnixNano())
+
+	config := DefaultConfig().
+		WithAppName("test-clock-timer").
+		WithLogDir("test-logs-clock-timer").
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithClock(func() time.Time { return time.Unix(0, now.Load()) })
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-clock-timer")
+
+	now.Add(int64(2 * time.Second))
+
+	nextPath := filepath.Join("test-logs-clock-timer", "test-clock-timer_2024-03-10.log")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(nextPath); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %s to be created without a log call", nextPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBackupDir(t *testing.T) {
+	var now atomic.Int64
+	now.Store(time.Date(2024, 3, 9, 23, 59, 59, 0, time.Local).UnixNano())
+
+	config := DefaultConfig().
+		WithAppName("test-backup").
+		WithLogDir("test-logs-backup").
+		WithBackupDir("test-logs-backup-archive").
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithClock(func() time.Time { return time.Unix(0, now.Load()) })
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-backup")
+	defer os.RemoveAll("test-logs-backup-archive")
+
+	logger.Info("Before midnight")
+	logger.Warn("Warning before midnight")
+
+	now.Add(int64(2 * time.Second))
+	logger.Info("After midnight")
+
+	for _, name := range []string{"test-backup_2024-03-09.log", "test-backup_error_2024-03-09.log"} {
+		if _, err := os.Stat(filepath.Join("test-logs-backup-archive", name)); err != nil {
+			t.Errorf("Expected %s in the backup dir: %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join("test-logs-backup", name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be moved out of the log dir", name)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join("test-logs-backup-archive", "test-backup_2024-03-09.log"))
+	if err != nil {
+		t.Fatalf("Failed to read archived file: %v", err)
+	}
+	if !strings.Contains(string(content), "Before midnight") {
+		t.Errorf("Expected archived file to keep its records, got: %s", content)
+	}
+
+	files, err := logger.GetLogFiles()
+	if err != nil {
+		t.Fatalf("Failed to get log files: %v", err)
+	}
+	archived := filepath.Join("test-logs-backup-archive", "test-backup_2024-03-09.log")
+	if !slices.Contains(files, archived) || !slices.Contains(files, "test-backup_2024-03-10.log") {
+		t.Errorf("Expected active and archived files, got %v", files)
+	}
+}
+
+func TestBackupDir_MoveFailure(t *testing.T) {
+	var now atomic.Int64
+	now.Store(time.Date(2024, 3, 9, 23, 59, 59, 0, time.Local).UnixNano())
+
+	dir := t.TempDir()
+	backupDir := filepath.Join(dir, "not-a-dir")
+	if err := os.WriteFile(backupDir, nil, 0o600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	config := DefaultConfig().
+		WithAppName("test-backup-fail").
+		WithLogDir(dir).
+		WithBackupDir(backupDir).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithAddSource(true).
+		WithClock(func() time.Time { return time.Unix(0, now.Load()) })
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("Before midnight")
+	now.Add(int64(2 * time.Second))
+	logger.Info("After midnight")
+
+	// The failure is reported once the rotation has released the lock
+	_, errorPath := logger.GetCurrentLogPaths()
+	var content []byte
+	for range 100 {
+		if content, _ = os.ReadFile(errorPath); strings.Contains(string(content), "Failed to move") {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(string(content), "Failed to move rotated log files") {
+		t.Fatalf("Expected the move failure to be logged, got: %s", content)
+	}
+	if !strings.Contains(string(content), "rotation.go:") || strings.Contains(string(content), "runtime/") {
+		t.Errorf("Expected the record to be attributed to the archiving code, got: %s", content)
+	}
+}
+
+func TestBoundAttrs(t *testing.T) {
+	logger := NewNop()
+	if attrs := logger.BoundAttrs(); len(attrs) != 0 {
+		t.Errorf("Expected no bound attributes, got %v", attrs)
+	}
+
+	derived := logger.
+		With("service", "api", slog.Int("port", 8080)).
+		With("request_id", "abc").
+		WithContext(context.Background()).
+		With("user", "alice")
+
+	got := slog.GroupValue(derived.BoundAttrs()...).String()
+	want := slog.GroupValue(
+		slog.String("service", "api"),
+		slog.Int("port", 8080),
+		slog.String("request_id", "abc"),
+		slog.Group("context", slog.String("user", "alice")),
+	).String()
+	if got != want {
+		t.Errorf("BoundAttrs() = %s, expected %s", got, want)
+	}
+
+	// Deriving must not change the parent
+	if attrs := logger.With("other", 1).BoundAttrs(); len(attrs) != 1 || attrs[0].Key != "other" {
+		t.Errorf("Unexpected attributes on sibling logger: %v", attrs)
+	}
+}
+
+func TestSuspend(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-suspend").
+		WithLogDir("test-logs-suspend").
+		WithConsoleOutput(false).
+		WithoutBuffering()
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-suspend")
+
+	derived := logger.With("component", "worker")
+
+	resume := logger.Suspend()
+	innerResume := logger.Suspend()
+	logger.Info("Muted message")
+	derived.Info("Derived message")
+	innerResume()
+	innerResume() // Calling twice must not resume the outer scope
+	logger.Info("Still muted message")
+	resume()
+	logger.Info("Resumed message")
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	output := string(content)
+
+	if strings.Contains(output, "Muted message") || strings.Contains(output, "Still muted message") {
+		t.Errorf("Expected no records while suspended, got: %s", output)
+	}
+	if !strings.Contains(output, "Derived message") {
+		t.Errorf("Expected other logger instances to keep logging, got: %s", output)
+	}
+	if !strings.Contains(output, "Resumed message") {
+		t.Errorf("Expected records after resume, got: %s", output)
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-reconfigure").
+		WithLogDir("test-logs-reconfigure").
+		WithConsoleOutput(false).
+		WithBufferSize(64 * 1024).
+		WithFlushInterval(time.Hour) // Never auto-flush during the test
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-reconfigure")
+
+	for i := 0; i < 50; i++ {
+		logger.Info("before reconfigure", "n", i)
+	}
+
+	if err := logger.Reconfigure(config.WithLogLevel(slog.LevelDebug)); err != nil {
+		t.Fatalf("Failed to reconfigure: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		logger.Debug("after reconfigure", "n", i)
+	}
+
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+
+	output := string(content)
+	if count := strings.Count(output, "before reconfigure"); count != 50 {
+		t.Errorf("Expected 50 lines logged before reconfigure, got %d", count)
+	}
+	if count := strings.Count(output, "after reconfigure"); count != 50 {
+		t.Errorf("Expected 50 debug lines logged after reconfigure, got %d", count)
+	}
+}
+
+func TestWith(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-with").
+		WithLogDir("test-logs-with").
+		WithLogLevel(slog.LevelDebug)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-with")
+
+	contextLogger := logger.With("user_id", 123, "session", "abc")
+	contextLogger.Info("Test message with context")
+
+	logger.Info("Original logger message")
+}
+
+func TestWith_AfterRotation(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-with-rotation").
+		WithLogDir("test-logs-with-rotation").
+		WithConsoleOutput(false).
+		WithoutBuffering()
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-with-rotation")
+
+	derived := logger.With("user_id", 42)
+	grouped := logger.WithContext(context.Background()).With("request_id", "abc")
+
+	if err := logger.RotateNow(); err != nil {
+		t.Fatalf("Failed to rotate logs: %v", err)
+	}
+	derived.Info("After rotate")
+
+	// Switch the parent to a new file name to simulate a new rotation target
+	if err := logger.Reconfigure(config.WithAppName("test-with-rotated")); err != nil {
+		t.Fatalf("Failed to reconfigure: %v", err)
+	}
+	derived.Info("After new file")
+	grouped.Warn("Grouped after new file")
+
+	oldPath := filepath.Join("test-logs-with-rotation", "test-with-rotation_"+time.Now().Format("2006-01-02")+".log")
+	oldContent, err := os.ReadFile(oldPath)
+	if err != nil {
+		t.Fatalf("Failed to read old info file: %v", err)
+	}
+	if !strings.Contains(string(oldContent), `msg="After rotate" user_id=42`) {
+		t.Errorf("Expected derived logger to write after RotateNow, got: %s", oldContent)
+	}
+
+	newPath, newErrorPath := logger.GetCurrentLogPaths()
+	newContent, err := os.ReadFile(newPath)
+	if err != nil {
+		t.Fatalf("Failed to read new info file: %v", err)
+	}
+	if !strings.Contains(string(newContent), `msg="After new file" user_id=42`) {
+		t.Errorf("Expected derived logger to write to the new file, got: %s", newContent)
+	}
+
+	newErrorContent, err := os.ReadFile(newErrorPath)
+	if err != nil {
+		t.Fatalf("Failed to read new error file: %v", err)
+	}
+	if !strings.Contains(string(newErrorContent), `msg="Grouped after new file" context.request_id=abc`) {
+		t.Errorf("Expected grouped logger to write to the new error file, got: %s", newErrorContent)
+	}
+}
+
+func TestWith_DedupAttrs(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-dedup").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithDedupAttrs(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.With("user_id", 1, "session", "abc").With("user_id", 2).Info("replaced")
+	logger.With("user_id", 1, "user_id", 2).Info("same call")
+	logger.With("user_id", 1).WithContext(context.Background()).With("user_id", 2).Info("grouped")
+
+	tail := logger.Tail()
+	expected := []string{
+		"msg=replaced session=abc user_id=2",
+		`msg="same call" user_id=2`,
+		"msg=grouped user_id=1 context.user_id=2",
+	}
+	for i, want := range expected {
+		if _, got, _ := strings.Cut(tail[i], "level=INFO "); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestFatal(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-fatal").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithBufferSize(64 * 1024).
+		WithFlushInterval(time.Hour).
+		WithFlushOnLevel(slog.LevelError + 4). // Keep the record buffered until Fatal syncs
+		WithFatalExitCode(3)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	var exitCode int
+	var content []byte
+	oldExit := exitFunc
+	exitFunc = func(code int) {
+		exitCode = code
+		_, errorPath := logger.GetCurrentLogPaths()
+		content, _ = os.ReadFile(errorPath)
+	}
+	defer func() { exitFunc = oldExit }()
+
+	logger.Fatal("Cannot open database", "attempts", 3)
+
+	if exitCode != 3 {
+		t.Errorf("Expected exit code 3, got %d", exitCode)
+	}
+	if !strings.Contains(string(content), `level=ERROR msg="Cannot open database" attempts=3`) {
+		t.Errorf("Expected the message to be in the file before exiting, got: %s", content)
+	}
+}
+
+func TestRotationHook(t *testing.T) {
+	var now atomic.Int64
+	now.Store(time.Date(2024, 3, 9, 12, 0, 0, 0, time.Local).UnixNano())
+
+	rotated := make(chan [2]string, 10)
+	config := DefaultConfig().
+		WithAppName("test-hook").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithClock(func() time.Time { return time.Unix(0, now.Load()) }).
+		WithRotationHook(func(oldPath, newPath string) {
+			rotated <- [2]string{oldPath, newPath}
+		})
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	receive := func() map[string]string {
+		t.Helper()
+		got := make(map[string]string)
+		for range 2 {
+			select {
+			case r := <-rotated:
+				got[r[0]] = r[1]
+			case <-time.After(time.Second):
+				t.Fatalf("Expected the hook to be called for the info and error files, got %v", got)
+			}
+		}
+		return got
+	}
+
+	oldInfo, oldError := logger.GetCurrentLogPaths()
+	now.Add(int64(24 * time.Hour))
+	if err := logger.RotateNow(); err != nil {
+		t.Fatalf("RotateNow failed: %v", err)
+	}
+	newInfo, newError := logger.GetCurrentLogPaths()
+
+	got := receive()
+	expected := map[string]string{oldInfo: newInfo, oldError: newError}
+	for oldPath, newPath := range expected {
+		if got[oldPath] != newPath {
+			t.Errorf("Expected %s to be replaced by %s, got %v", oldPath, newPath, got)
+		}
+	}
+}
+
+func TestDailySummary(t *testing.T) {
+	var now atomic.Int64
+	now.Store(time.Date(2024, 3, 9, 23, 0, 0, 0, time.Local).UnixNano())
+
+	config := DefaultConfig().
+		WithAppName("test-summary").
+		WithLogDir(t.TempDir()).
+		WithLogLevel(slog.LevelDebug).
+		WithConsoleOutput(false).
+		WithDailySummary(true).
+		WithClock(func() time.Time { return time.Unix(0, now.Load()) })
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Debug("d")
+	for range 3 {
+		logger.Info("i")
+	}
+	logger.Warn("w")
+	logger.Warn("w")
+	logger.Error("e")
+	oldInfo, _ := logger.GetCurrentLogPaths()
+
+	now.Add(int64(2 * time.Hour))
+	if err := logger.RotateNow(); err != nil {
+		t.Fatalf("RotateNow failed: %v", err)
+	}
+	logger.Info("new day")
+
+	old, err := os.ReadFile(oldInfo)
+	if err != nil {
+		t.Fatalf("Failed to read outgoing file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(old)), "\n")
+	summary := lines[len(lines)-1]
+	if !strings.Contains(summary, `msg="daily summary" date=2024-03-09 debug=1 info=3 warn=2 error=1`) {
+		t.Errorf("Expected the summary as the last line of the outgoing file, got: %s", summary)
+	}
+
+	// Counters start over for the new day
+	secondInfo, _ := logger.GetCurrentLogPaths()
+	now.Add(int64(24 * time.Hour))
+	logger.RotateNow()
+	content, err := os.ReadFile(secondInfo)
+	if err != nil {
+		t.Fatalf("Failed to read second day's file: %v", err)
+	}
+	if !strings.Contains(string(content), `date=2024-03-10 debug=0 info=1 warn=0 error=0`) {
+		t.Errorf("Expected the second day's summary to count only its own records, got: %s", content)
+	}
+}
+
+func TestGlobalLogger(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-global").
+		WithLogDir("test-logs-global").
+		WithLogLevel(slog.LevelDebug)
+
+	err := Init(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize global logger: %v", err)
+	}
+	defer Close()
+	defer os.RemoveAll("test-logs-global")
+
+	Debug("Global debug message")
+	Info("Global info message")
+	Warn("Global warning message")
+	Error("Global error message")
+
+	contextLogger := With("global_key", "global_value")
+	if contextLogger == nil {
+		t.Error("Expected non-nil logger from global With()")
+	}
+}
+
+func TestFlushAllAndCloseAll(t *testing.T) {
+	dir := t.TempDir()
+
+	var loggers []*Logger
+	for _, name := range []string{"orders", "billing", "audit"} {
+		logger, err := New(DefaultConfig().
+			WithAppName(name).
+			WithLogDir(dir).
+			WithConsoleOutput(false).
+			WithBufferSize(64 * 1024).
+			WithFlushInterval(time.Hour).
+			WithRegister(true))
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		defer logger.Close()
+
+		logger.Info("buffered record", "logger", name)
+		loggers = append(loggers, logger)
+	}
+
+	if err := FlushAll(); err != nil {
+		t.Fatalf("FlushAll failed: %v", err)
+	}
+	for _, logger := range loggers {
+		infoPath, _ := logger.GetCurrentLogPaths()
+		content, err := os.ReadFile(infoPath)
+		if err != nil {
+			t.Fatalf("Failed to read info file: %v", err)
+		}
+		if !strings.Contains(string(content), "buffered record") {
+			t.Errorf("Expected FlushAll to flush %s, got: %q", infoPath, content)
+		}
+	}
+
+	if err := CloseAll(); err != nil {
+		t.Fatalf("CloseAll failed: %v", err)
+	}
+	registryMu.Lock()
+	remaining := len(registry)
+	registryMu.Unlock()
+	if remaining != 0 {
+		t.Errorf("Expected CloseAll to empty the registry, got %d loggers", remaining)
+	}
+}
+
+func TestNamedLoggers(t *testing.T) {
+	dir := t.TempDir()
+	newLogger := func(name string) *Logger {
+		logger, err := New(DefaultConfig().WithAppName(name).WithLogDir(dir).WithConsoleOutput(false))
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		return logger
+	}
+
+	payments, auth := newLogger("payments"), newLogger("auth")
+	defer payments.Close()
+	RegisterLogger("payments", payments)
+	RegisterLogger("auth", auth)
+
+	if GetLogger("payments") != payments || GetLogger("auth") != auth {
+		t.Error("Expected GetLogger to return the logger registered under each name")
+	}
+	if GetLogger("unknown") != nil {
+		t.Error("Expected nil for an unknown name")
+	}
+
+	auth.Close()
+	if GetLogger("auth") != nil {
+		t.Error("Expected a closed logger to be removed from the registry")
+	}
+
+	RegisterLogger("payments", nil)
+	if GetLogger("payments") != nil {
+		t.Error("Expected registering nil to remove the name")
+	}
+}
+
+func TestAddSource_CallerLocation(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-source").
+		WithLogDir("test-logs-source").
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithAddSource(true)
 
 	logger, err := New(config)
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
 	defer logger.Close()
-	defer os.RemoveAll("test-logs-levels")
+	defer os.RemoveAll("test-logs-source")
 
-	logger.Debug("Debug message", "key", "value")
-	logger.Info("Info message", "key", "value")
-	logger.Warn("Warning message", "key", "value")
-	logger.Error("Error message", "key", "value")
+	logger.Info("direct call")
+	logger.With("k", "v").Info("derived call")
+	logger.Printf("printf call")
+	logger.StdLogger(slog.LevelInfo).Print("std logger call")
+	logger.Slog().Info("slog call")
 
-	today := time.Now().Format("2006-01-02")
-	infoPath := filepath.Join("test-logs-levels", "test-levels_"+today+".log")
-	errorPath := filepath.Join("test-logs-levels", "test-levels_error_"+today+".log")
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
 
-	if _, err := os.Stat(infoPath); os.IsNotExist(err) {
-		t.Error("Info log file was not created")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 records, got %d: %s", len(lines), content)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "logger_test.go:") {
+			t.Errorf("Expected source to point at logger_test.go, got: %s", line)
+		}
 	}
+}
 
-	if _, err := os.Stat(errorPath); os.IsNotExist(err) {
-		t.Error("Error log file was not created")
+func TestSourceFormat(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	pkgFile := filepath.Base(filepath.Dir(file)) + "/logger_test.go:"
+
+	tests := []struct {
+		format   SourceFormat
+		contains string
+	}{
+		{SourceShort, "source=logger_test.go:"},
+		{SourcePackage, "source=" + pkgFile},
+		{SourceFull, "source=" + file + ":"},
+		{"", "source=" + file + ":"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			config := DefaultConfig().
+				WithAppName("test-source-format").
+				WithLogDir(t.TempDir()).
+				WithConsoleOutput(false).
+				WithInMemoryTail(1).
+				WithAddSource(true).
+				WithSourceFormat(tt.format)
+
+			logger, err := New(config)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer logger.Close()
+
+			logger.Info("Source message")
+
+			line := logger.Tail()[0]
+			if !strings.Contains(line, tt.contains) {
+				t.Errorf("Expected source containing %q, got: %s", tt.contains, line)
+			}
+		})
 	}
 }
 
-func TestLogLevelChange(t *testing.T) {
+func TestBuiltinKeys(t *testing.T) {
 	config := DefaultConfig().
-		WithAppName("test-level").
-		WithLogDir("test-logs-level").
-		WithLogLevel(slog.LevelWarn) // Start with WARN level
+		WithAppName("test-keys").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithJSONFormat(true).
+		WithBufferSize(4096).
+		WithFlushInterval(time.Hour).
+		WithTimeKey("@timestamp").
+		WithLevelKey("severity").
+		WithMessageKey("message")
 
 	logger, err := New(config)
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
 	defer logger.Close()
-	defer os.RemoveAll("test-logs-level")
 
-	if logger.config.LogLevel != slog.LevelWarn {
-		t.Error("Expected log level to be WARN initially")
-	}
+	// ERROR records must still be flushed immediately under the renamed level key
+	logger.Error("Renamed keys", "user", "alice")
 
-	err = logger.SetLevel(slog.LevelDebug)
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
 	if err != nil {
-		t.Errorf("Failed to change log level: %v", err)
+		t.Fatalf("Failed to read error file: %v", err)
 	}
 
-	if logger.config.LogLevel != slog.LevelDebug {
-		t.Error("Expected log level to be DEBUG after SetLevel(DEBUG)")
+	var entry map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(content), &entry); err != nil {
+		t.Fatalf("Expected one JSON record in the error file, got %q: %v", content, err)
+	}
+	for _, key := range []string{"time", "level", "msg"} {
+		if _, exists := entry[key]; exists {
+			t.Errorf("Expected %q to be renamed, got: %s", key, content)
+		}
+	}
+	if _, exists := entry["@timestamp"]; !exists {
+		t.Errorf("Expected @timestamp key, got: %s", content)
+	}
+	if entry["severity"] != "ERROR" || entry["message"] != "Renamed keys" || entry["user"] != "alice" {
+		t.Errorf("Unexpected record: %s", content)
 	}
 }
 
-func TestWith(t *testing.T) {
+func TestECSFormat(t *testing.T) {
 	config := DefaultConfig().
-		WithAppName("test-with").
-		WithLogDir("test-logs-with").
-		WithLogLevel(slog.LevelDebug)
+		WithAppName("test-ecs").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithMessageKey("text"). // Replaced by ECS names
+		WithECSFormat(true).
+		WithAddSource(true).
+		WithSourceFormat(SourceShort)
+
+	if config.MessageKey != "" || !config.JSONFormat {
+		t.Fatalf("Expected ECS format to clear custom keys and enable JSON, got %+v", config)
+	}
 
 	logger, err := New(config)
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
 	defer logger.Close()
-	defer os.RemoveAll("test-logs-with")
-
-	contextLogger := logger.With("user_id", 123, "session", "abc")
-	contextLogger.Info("Test message with context")
 
-	logger.Info("Original logger message")
-}
-
-func TestGlobalLogger(t *testing.T) {
-	config := DefaultConfig().
-		WithAppName("test-global").
-		WithLogDir("test-logs-global").
-		WithLogLevel(slog.LevelDebug)
+	logger.Error("Payment failed", "order_id", 42)
 
-	err := Init(config)
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
 	if err != nil {
-		t.Fatalf("Failed to initialize global logger: %v", err)
+		t.Fatalf("Failed to read error file: %v", err)
 	}
-	defer Close()
-	defer os.RemoveAll("test-logs-global")
 
-	Debug("Global debug message")
-	Info("Global info message")
-	Warn("Global warning message")
-	Error("Global error message")
+	var entry map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(content), &entry); err != nil {
+		t.Fatalf("Expected one JSON record in the error file, got %q: %v", content, err)
+	}
+	if _, exists := entry["@timestamp"]; !exists {
+		t.Errorf("Expected @timestamp key, got: %s", content)
+	}
+	if entry["log.level"] != "ERROR" || entry["message"] != "Payment failed" || entry["order_id"] != float64(42) {
+		t.Errorf("Unexpected ECS record: %s", content)
+	}
 
-	contextLogger := With("global_key", "global_value")
-	if contextLogger == nil {
-		t.Error("Expected non-nil logger from global With()")
+	origin, _ := entry["log.origin"].(map[string]any)
+	file, _ := origin["file"].(map[string]any)
+	if file["name"] != "logger_test.go" || file["line"] == nil {
+		t.Errorf("Expected log.origin.file with name and line, got: %s", content)
+	}
+	if function, _ := origin["function"].(string); !strings.HasSuffix(function, "TestECSFormat") {
+		t.Errorf("Expected log.origin.function, got: %s", content)
+	}
+	if _, exists := entry["source"]; exists {
+		t.Errorf("Expected source to be replaced by log.origin, got: %s", content)
 	}
 }
 
@@ -280,9 +1430,289 @@ func TestCleanup(t *testing.T) {
 	}
 }
 
+func TestCleanup_Sync(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-cleanup-sync").
+		WithLogDir("test-logs-cleanup-sync").
+		WithConsoleOutput(false).
+		WithRetentionDays(1)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-cleanup-sync")
+
+	// Let the initial background cleanup run before creating the old file
+	time.Sleep(50 * time.Millisecond)
+
+	oldName := "test-cleanup-sync_" + time.Now().AddDate(0, 0, -2).Format("2006-01-02") + ".log"
+	oldFile := filepath.Join("test-logs-cleanup-sync", oldName)
+	if err := os.WriteFile(oldFile, nil, 0o600); err != nil {
+		t.Fatalf("Failed to create old test file: %v", err)
+	}
+	twoDaysAgo := time.Now().AddDate(0, 0, -2)
+	os.Chtimes(oldFile, twoDaysAgo, twoDaysAgo)
+
+	removed, err := logger.Cleanup()
+	if err != nil {
+		t.Fatalf("Cleanup returned error: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != oldName {
+		t.Errorf("Expected removed files [%s], got %v", oldName, removed)
+	}
+	if _, err := os.Stat(oldFile); !os.IsNotExist(err) {
+		t.Error("Expected old log file to be removed")
+	}
+}
+
+func TestCleanupDryRun(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-cleanup-dry").
+		WithLogDir("test-logs-cleanup-dry").
+		WithConsoleOutput(false).
+		WithRetentionDays(1)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-cleanup-dry")
+
+	// Let the initial background cleanup run before creating the old file
+	time.Sleep(50 * time.Millisecond)
+
+	oldName := "test-cleanup-dry_" + time.Now().AddDate(0, 0, -2).Format("2006-01-02") + ".log"
+	oldFile := filepath.Join("test-logs-cleanup-dry", oldName)
+	if err := os.WriteFile(oldFile, nil, 0o600); err != nil {
+		t.Fatalf("Failed to create old test file: %v", err)
+	}
+	twoDaysAgo := time.Now().AddDate(0, 0, -2)
+	os.Chtimes(oldFile, twoDaysAgo, twoDaysAgo)
+
+	candidates, err := logger.CleanupDryRun()
+	if err != nil {
+		t.Fatalf("CleanupDryRun returned error: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0] != oldName {
+		t.Errorf("Expected candidates [%s], got %v", oldName, candidates)
+	}
+	if _, err := os.Stat(oldFile); err != nil {
+		t.Errorf("Expected old log file to be kept by dry run: %v", err)
+	}
+}
+
+func TestCleanup_MissingDir(t *testing.T) {
+	logger := &Logger{loggerCore: &loggerCore{config: DefaultConfig().WithLogDir("test-logs-does-not-exist")}}
+
+	if _, err := logger.Cleanup(); err == nil {
+		t.Error("Expected error when the log directory cannot be read")
+	}
+}
+
+func TestCleanup_MaxTotalSize(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-cleanup-size").
+		WithLogDir("test-logs-cleanup-size").
+		WithConsoleOutput(false).
+		WithMaxTotalSize(2500)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-cleanup-size")
+
+	// Three recent files, well within RetentionDays, oldest first
+	var paths []string
+	for i := 3; i >= 1; i-- {
+		date := time.Now().AddDate(0, 0, -i).Format("2006-01-02")
+		path := filepath.Join("test-logs-cleanup-size", "test-cleanup-size_"+date+".log")
+		if err := os.WriteFile(path, make([]byte, 1000), 0o600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		modTime := time.Now().Add(-time.Duration(i) * time.Hour)
+		os.Chtimes(path, modTime, modTime)
+		paths = append(paths, path)
+	}
+
+	logger.performCleanup()
+
+	if _, err := os.Stat(paths[0]); !os.IsNotExist(err) {
+		t.Error("Expected oldest log file to be removed to fit the size cap")
+	}
+	for _, path := range paths[1:] {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected newer log file %s to be kept: %v", path, err)
+		}
+	}
+
+	infoPath, errorPath := logger.GetCurrentLogPaths()
+	for _, path := range []string{infoPath, errorPath} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected active log file %s to be kept: %v", path, err)
+		}
+	}
+}
+
+func TestCleanup_MaxFiles(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-cleanup-count").
+		WithLogDir("test-logs-cleanup-count").
+		WithConsoleOutput(false).
+		WithMaxFiles(4)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-cleanup-count")
+
+	// Info and error files for the three previous days
+	for i := 1; i <= 3; i++ {
+		date := time.Now().AddDate(0, 0, -i).Format("2006-01-02")
+		for _, name := range []string{"test-cleanup-count_" + date + ".log", "test-cleanup-count_error_" + date + ".log"} {
+			if err := os.WriteFile(filepath.Join("test-logs-cleanup-count", name), []byte("old\n"), 0o600); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+		}
+	}
+
+	logger.performCleanup()
+
+	files, err := logger.GetLogFiles()
+	if err != nil {
+		t.Fatalf("Failed to get log files: %v", err)
+	}
+	if len(files) != 4 {
+		t.Fatalf("Expected 4 log files to survive, got %v", files)
+	}
+
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	today := time.Now().Format("2006-01-02")
+	for _, file := range files {
+		if !strings.HasSuffix(file, "_"+yesterday+".log") && !strings.HasSuffix(file, "_"+today+".log") {
+			t.Errorf("Expected only the newest files to survive, found %s", file)
+		}
+	}
+}
+
+func TestCleanup_Interval(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-cleanup-interval").
+		WithLogDir("test-logs-cleanup-interval").
+		WithConsoleOutput(false).
+		WithRetentionDays(1).
+		WithCleanupInterval(100 * time.Millisecond)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-cleanup-interval")
+
+	// Let the initial cleanup run before creating the old file
+	time.Sleep(50 * time.Millisecond)
+
+	oldDate := time.Now().AddDate(0, 0, -2).Format("2006-01-02")
+	oldFile := filepath.Join("test-logs-cleanup-interval", "test-cleanup-interval_"+oldDate+".log")
+	if err := os.WriteFile(oldFile, nil, 0o600); err != nil {
+		t.Fatalf("Failed to create old test file: %v", err)
+	}
+	twoDaysAgo := time.Now().AddDate(0, 0, -2)
+	os.Chtimes(oldFile, twoDaysAgo, twoDaysAgo)
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(oldFile); os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Error("Expected old log file to be removed by the periodic cleanup")
+}
+
+func TestClose_StopsBackgroundGoroutines(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-close-goroutines").
+		WithLogDir("test-logs-close-goroutines").
+		WithConsoleOutput(false).
+		WithoutBuffering()
+	defer os.RemoveAll("test-logs-close-goroutines")
+
+	// Let goroutines from earlier tests settle
+	time.Sleep(50 * time.Millisecond)
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 10; i++ {
+		logger, err := New(config)
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		logger.Info("short-lived logger")
+		logger.Close()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expected background goroutines to exit after Close, had %d before and %d after", before, after)
+	}
+}
+
+func TestOnClose(t *testing.T) {
+	dir := t.TempDir()
+	errRemote := errors.New("remote flush failed")
+	var order []string
+	config := DefaultConfig().
+		WithAppName("test-on-close").
+		WithLogDir(dir).
+		WithConsoleOutput(false).
+		WithOnClose(func() error {
+			order = append(order, "first")
+			return nil
+		}).
+		WithOnClose(func() error {
+			// The files are already flushed when hooks run
+			infoPath := filepath.Join(dir, "test-on-close_"+time.Now().Format("2006-01-02")+".log")
+			content, _ := os.ReadFile(infoPath)
+			if !strings.Contains(string(content), "buffered record") {
+				t.Errorf("Expected the buffered record on disk before the hook, got: %s", content)
+			}
+			order = append(order, "second")
+			return errRemote
+		})
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Info("buffered record")
+
+	if err := logger.Close(); !errors.Is(err, errRemote) {
+		t.Errorf("Expected the hook error from Close, got %v", err)
+	}
+	if strings.Join(order, ",") != "second,first" {
+		t.Errorf("Expected hooks in reverse order, got %v", order)
+	}
+
+	// A second Close doesn't run the hooks again
+	logger.Close()
+	if len(order) != 2 {
+		t.Errorf("Expected hooks to run once, got %v", order)
+	}
+}
+
 func TestIsOurLogFile(t *testing.T) {
 	config := DefaultConfig().WithAppName("myapp")
-	logger := &Logger{config: config}
+	logger := &Logger{loggerCore: &loggerCore{config: config}}
 
 	tests := []struct {
 		filename string
@@ -290,6 +1720,8 @@ func TestIsOurLogFile(t *testing.T) {
 	}{
 		{"myapp_2024-01-01.log", true},
 		{"myapp_error_2024-01-01.log", true},
+		{"myapp_debug_2024-01-01.log", true},
+		{"myapp_warn_2024-01-01.log", true},
 		{"otherapp_2024-01-01.log", false},
 		{"myapp.txt", false},
 		{"random.log", false},
@@ -325,6 +1757,43 @@ func BenchmarkLogging(b *testing.B) {
 	})
 }
 
+func BenchmarkDisabledLevel(b *testing.B) {
+	config := DefaultConfig().
+		WithAppName("bench-disabled").
+		WithLogDir(b.TempDir()).
+		WithConsoleOutput(false).
+		WithLogLevel(slog.LevelInfo)
+
+	logger, err := New(config)
+	if err != nil {
+		b.Fatalf("Failed to create logger: %v", err)
+	}
+	SetGlobalLogger(logger)
+	defer SetGlobalLogger(nil) // Closes the logger
+
+	b.Run("method", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			logger.Debug("Disabled message", "iteration", 1)
+		}
+	})
+	b.Run("global", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			Debug("Disabled message", "iteration", 1)
+		}
+	})
+	// The full path every disabled record took before the level check moved up front
+	b.Run("without fast path", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var pcs [1]uintptr
+			runtime.Callers(1, pcs[:])
+			logger.logPC(pcs[0], slog.LevelDebug, "Disabled message", "iteration", 1)
+		}
+	})
+}
+
 func TestLogger_BufferedWrites(t *testing.T) {
 	tempDir := filepath.Join(os.TempDir(), "islogger_buffer_test")
 	defer os.RemoveAll(tempDir)
@@ -490,3 +1959,104 @@ func TestLogger_BufferedWritesImmediateFlushOnError(t *testing.T) {
 		t.Fatal("Error file should immediately contain error message")
 	}
 }
+
+func TestLogger_FlushOnLevelFormats(t *testing.T) {
+	formats := map[string]func(Config) Config{
+		"text":   func(c Config) Config { return c },
+		"json":   func(c Config) Config { return c.WithJSONFormat(true) },
+		"gelf":   func(c Config) Config { return c.WithGELFFormat(true) }, // Numeric levels
+		"logfmt": func(c Config) Config { return c.WithLogfmtFormat(true) },
+	}
+
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			config := format(DefaultConfig().
+				WithAppName("test-flush-" + name).
+				WithLogDir(t.TempDir()).
+				WithConsoleOutput(false).
+				WithBufferSize(64 * 1024).
+				WithFlushInterval(time.Hour).
+				WithFlushOnLevel(slog.LevelError))
+
+			logger, err := New(config)
+			if err != nil {
+				t.Fatalf("Failed to create logger: %v", err)
+			}
+			defer logger.Close()
+
+			logger.Warn("stays buffered")
+			logger.Error("boom")
+
+			_, errorPath := logger.GetCurrentLogPaths()
+			content, err := os.ReadFile(errorPath)
+			if err != nil {
+				t.Fatalf("Failed to read error file: %v", err)
+			}
+			if !strings.Contains(string(content), "boom") {
+				t.Errorf("Expected the error to be flushed immediately, got: %q", content)
+			}
+		})
+	}
+}
+
+func TestLogger_Sync(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-sync").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithBufferSize(64 * 1024).
+		WithFlushInterval(time.Hour)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("Buffered info message")
+	logger.Warn("Buffered warning message")
+
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	infoPath, errorPath := logger.GetCurrentLogPaths()
+	infoContent, _ := os.ReadFile(infoPath)
+	errorContent, _ := os.ReadFile(errorPath)
+	if !strings.Contains(string(infoContent), "Buffered info message") {
+		t.Errorf("Expected info message on disk after Sync, got: %s", infoContent)
+	}
+	if !strings.Contains(string(errorContent), "Buffered warning message") {
+		t.Errorf("Expected warning message on disk after Sync, got: %s", errorContent)
+	}
+}
+
+func TestLogger_SyncOnError(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-sync-error").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithBufferSize(64 * 1024).
+		WithFlushInterval(time.Hour).
+		WithFlushOnLevel(slog.Level(100)). // Never flush on level
+		WithSyncOnError(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("Info before error")
+	logger.Error("Critical failure")
+
+	infoPath, errorPath := logger.GetCurrentLogPaths()
+	infoContent, _ := os.ReadFile(infoPath)
+	errorContent, _ := os.ReadFile(errorPath)
+	if !strings.Contains(string(errorContent), "Critical failure") {
+		t.Errorf("Expected error record on disk right after logging, got: %s", errorContent)
+	}
+	if !strings.Contains(string(infoContent), "Info before error") {
+		t.Errorf("Expected earlier records to be synced too, got: %s", infoContent)
+	}
+}
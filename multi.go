@@ -0,0 +1,31 @@
+package iSlogger
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// MultiLogger returns a Logger that sends every record to all given loggers.
+// With, WithContext and WithError apply to every child, each child keeps its own
+// level, format and files, and Flush, Sync and Close reach all children.
+func MultiLogger(loggers ...*Logger) *Logger {
+	handlers := make([]slog.Handler, len(loggers))
+	for i, logger := range loggers {
+		handlers[i] = logger.Handler()
+	}
+
+	l := NewWithHandler(newMultiHandler(handlers...))
+	l.children = loggers
+	return l
+}
+
+// forEachChild calls fn for every child of a MultiLogger, joining the errors
+func (c *loggerCore) forEachChild(fn func(*Logger) error) error {
+	var errs []error
+	for _, child := range c.children {
+		if err := fn(child); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
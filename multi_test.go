@@ -0,0 +1,53 @@
+package iSlogger
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMultiLogger(t *testing.T) {
+	jsonLogger, err := New(DefaultConfig().
+		WithAppName("test-multi-json").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithJSONFormat(true))
+	if err != nil {
+		t.Fatalf("Failed to create JSON logger: %v", err)
+	}
+	textLogger, err := New(DefaultConfig().
+		WithAppName("test-multi-text").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false))
+	if err != nil {
+		t.Fatalf("Failed to create text logger: %v", err)
+	}
+
+	logger := MultiLogger(jsonLogger, textLogger)
+	logger.With("request_id", "r1").Info("Broadcast message")
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if len(jsonLogger.files) != 0 || len(textLogger.files) != 0 {
+		t.Error("Expected Close to close all children")
+	}
+
+	jsonPath, _ := jsonLogger.GetCurrentLogPaths()
+	jsonContent, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON log file: %v", err)
+	}
+	if !strings.Contains(string(jsonContent), `"msg":"Broadcast message","request_id":"r1"`) {
+		t.Errorf("Expected broadcast record in JSON file, got: %s", jsonContent)
+	}
+
+	textPath, _ := textLogger.GetCurrentLogPaths()
+	textContent, err := os.ReadFile(textPath)
+	if err != nil {
+		t.Fatalf("Failed to read text log file: %v", err)
+	}
+	if !strings.Contains(string(textContent), `msg="Broadcast message" request_id=r1`) {
+		t.Errorf("Expected broadcast record in text file, got: %s", textContent)
+	}
+}
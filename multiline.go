@@ -0,0 +1,111 @@
+package iSlogger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// multilineHandler wraps a text handler so string values containing newlines,
+// such as stack traces, are written below the record on indented continuation
+// lines instead of being escaped into a single line:
+//
+//	time=... level=ERROR msg="request failed" user=alice
+//	  stacktrace:
+//	    goroutine 1 [running]:
+//	    main.main()
+type multilineHandler struct {
+	handler slog.Handler // Text handler writing into out
+	out     *bytes.Buffer
+	w       io.Writer
+	mu      *sync.Mutex // Guards out, shared with derived handlers
+	prefix  string      // Dotted group prefix for the keys of new attributes
+}
+
+// newMultilineHandler creates a text handler writing to w
+func newMultilineHandler(w io.Writer, opts *slog.HandlerOptions) *multilineHandler {
+	out := &bytes.Buffer{}
+	return &multilineHandler{handler: slog.NewTextHandler(out, opts), out: out, w: w, mu: &sync.Mutex{}}
+}
+
+// Enabled reports whether the text handler is enabled for the level
+func (h *multilineHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle writes the record without its multi-line values, followed by the values,
+// in a single write so the tail and other records never split them
+func (h *multilineHandler) Handle(ctx context.Context, record slog.Record) error {
+	var attrs []slog.Attr
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	kept, multiline := splitMultiline(h.prefix, attrs)
+	if len(multiline) > 0 {
+		record = slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+		record.AddAttrs(kept...)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.out.Reset()
+	if err := h.handler.Handle(ctx, record); err != nil {
+		return err
+	}
+	for _, attr := range multiline {
+		h.out.WriteString("  " + attr.Key + ":\n")
+		for line := range strings.Lines(strings.TrimRight(attr.Value.String(), "\n")) {
+			h.out.WriteString("    " + line)
+		}
+		h.out.WriteByte('\n')
+	}
+	_, err := h.w.Write(h.out.Bytes())
+	return err
+}
+
+// splitMultiline separates string values containing newlines, keyed by their
+// dotted path, from the rest. Groups keep their other attributes.
+func splitMultiline(prefix string, attrs []slog.Attr) (kept, multiline []slog.Attr) {
+	for _, attr := range attrs {
+		attr.Value = attr.Value.Resolve()
+		switch {
+		case attr.Value.Kind() == slog.KindString && strings.Contains(attr.Value.String(), "\n"):
+			multiline = append(multiline, slog.String(prefix+attr.Key, attr.Value.String()))
+		case attr.Value.Kind() == slog.KindGroup:
+			nestedPrefix := prefix
+			if attr.Key != "" {
+				nestedPrefix = prefix + attr.Key + "."
+			}
+			nestedKept, nestedMultiline := splitMultiline(nestedPrefix, attr.Value.Group())
+			multiline = append(multiline, nestedMultiline...)
+			if len(nestedKept) > 0 {
+				kept = append(kept, slog.Attr{Key: attr.Key, Value: slog.GroupValue(nestedKept...)})
+			}
+		default:
+			kept = append(kept, attr)
+		}
+	}
+	return kept, multiline
+}
+
+// WithAttrs creates a new handler with additional attributes
+func (h *multilineHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.handler = h.handler.WithAttrs(attrs)
+	return &clone
+}
+
+// WithGroup creates a new handler with a group
+func (h *multilineHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.handler = h.handler.WithGroup(name)
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
@@ -0,0 +1,73 @@
+package iSlogger
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMultilineValues(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-multiline").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithInMemoryTail(10).
+		WithMultilineValues(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	trace := "goroutine 1 [running]:\nmain.main()\n\t/app/main.go:12\n"
+	logger.Error("Request failed", "user", "alice", "stacktrace", trace, slog.Group("db", "query", "SELECT 1\nFROM dual"))
+	logger.Info("Single line", "user", "bob")
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+
+	expected := `level=ERROR msg="Request failed" user=alice` + "\n" +
+		"  stacktrace:\n" +
+		"    goroutine 1 [running]:\n" +
+		"    main.main()\n" +
+		"    \t/app/main.go:12\n" +
+		"  db.query:\n" +
+		"    SELECT 1\n" +
+		"    FROM dual\n"
+	if _, record, _ := strings.Cut(string(content), " "); record != expected {
+		t.Errorf("Expected indented continuation lines, got:\n%s", content)
+	}
+
+	tail := logger.Tail()
+	if len(tail) != 2 || !strings.HasSuffix(tail[0], "    FROM dual") || !strings.Contains(tail[1], "msg=\"Single line\" user=bob") {
+		t.Errorf("Expected one tail entry per record, got %q", tail)
+	}
+}
+
+func TestMultilineValues_JSONUnaffected(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-multiline-json").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithInMemoryTail(1).
+		WithJSONFormat(true).
+		WithMultilineValues(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("Trace", "stacktrace", "line one\nline two")
+
+	if line := logger.Tail()[0]; !strings.Contains(line, `"stacktrace":"line one\nline two"`) {
+		t.Errorf("Expected JSON to keep escaped newlines, got: %s", line)
+	}
+}
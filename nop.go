@@ -0,0 +1,23 @@
+package iSlogger
+
+import "log/slog"
+
+// NewNop returns a Logger that discards all records.
+// It opens no files and starts no goroutines, so it is safe to use in tests
+// and as a default when logging is disabled.
+func NewNop() *Logger {
+	return NewWithHandler(slog.DiscardHandler)
+}
+
+// NewWithHandler returns a Logger that sends every record to handler once.
+// Like NewNop it opens no files and starts no goroutines; file, filter and
+// retention settings do not apply.
+func NewWithHandler(handler slog.Handler) *Logger {
+	return &Logger{
+		loggerCore: &loggerCore{
+			infoLogger:  slog.New(handler),
+			errorLogger: slog.New(slog.DiscardHandler),
+			nop:         true,
+		},
+	}
+}
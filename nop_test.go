@@ -0,0 +1,95 @@
+package iSlogger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewNop(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	logger := NewNop()
+
+	logger.Debug("debug")
+	logger.Info("info", "key", "value")
+	logger.Warn("warn")
+	logger.Error("error")
+	logger.Printf("printf %d", 1)
+	logger.Println("println")
+	logger.StdLogger(slog.LevelInfo).Print("std")
+	logger.Slog().Info("slog")
+
+	derived := logger.With("key", "value").WithContext(context.Background()).WithError(errors.New("boom"))
+	derived.Error("derived")
+
+	if err := logger.SetLevel(slog.LevelDebug); err != nil {
+		t.Errorf("SetLevel returned error: %v", err)
+	}
+	if err := logger.Reconfigure(DefaultConfig()); err != nil {
+		t.Errorf("Reconfigure returned error: %v", err)
+	}
+	if err := logger.RotateNow(); err != nil {
+		t.Errorf("RotateNow returned error: %v", err)
+	}
+	if err := logger.Flush(); err != nil {
+		t.Errorf("Flush returned error: %v", err)
+	}
+	logger.CleanupNow()
+
+	files, err := logger.GetLogFiles()
+	if err != nil || len(files) != 0 {
+		t.Errorf("Expected no log files, got %v (err %v)", files, err)
+	}
+	if infoPath, errorPath := logger.GetCurrentLogPaths(); infoPath != "" || errorPath != "" {
+		t.Errorf("Expected empty log paths, got %q and %q", infoPath, errorPath)
+	}
+	if tail := logger.Tail(); tail != nil {
+		t.Errorf("Expected nil tail, got %v", tail)
+	}
+
+	if err := derived.Close(); err != nil {
+		t.Errorf("Close returned error: %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Errorf("Close returned error: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no files to be created, found %d entries", len(entries))
+	}
+}
+
+func TestNewWithHandler(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	var buf bytes.Buffer
+	logger := NewWithHandler(slog.NewTextHandler(&buf, nil))
+
+	logger.With("key", "value").Error("Handled once")
+
+	if got := strings.Count(buf.String(), "Handled once"); got != 1 {
+		t.Errorf("Expected the record to reach the handler once, got %d: %s", got, buf.String())
+	}
+	if !strings.Contains(buf.String(), "key=value") {
+		t.Errorf("Expected bound attributes, got: %s", buf.String())
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no files to be created, found %d entries", len(entries))
+	}
+}
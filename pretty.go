@@ -0,0 +1,27 @@
+package iSlogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// prettyJSONWriter re-indents compact JSON records for human readers
+type prettyJSONWriter struct {
+	writer io.Writer
+}
+
+// Write indents the record and writes it, falling back to the original bytes
+// if the record is not valid JSON
+func (pw *prettyJSONWriter) Write(p []byte) (n int, err error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimRight(p, "\n"), "", "  "); err != nil {
+		return pw.writer.Write(p)
+	}
+	buf.WriteByte('\n')
+
+	if _, err := pw.writer.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,254 @@
+package iSlogger
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ProtoEncoder encodes one record for the binary writer. Attributes keep their
+// groups; bound attributes come before the record's own.
+type ProtoEncoder func(level slog.Level, t time.Time, msg string, attrs []slog.Attr) []byte
+
+// Protobuf wire types and field numbers of the default encoding:
+//
+//	message Record {
+//	  int64  time_unix_nano = 1;
+//	  sint32 level          = 2;
+//	  string message        = 3;
+//	  repeated Attr attrs   = 4;
+//	}
+//	message Attr {
+//	  string key   = 1; // Dotted path for grouped attributes
+//	  string value = 2;
+//	}
+const (
+	protoVarint = 0
+	protoBytes  = 2
+
+	protoFieldTime    = 1
+	protoFieldLevel   = 2
+	protoFieldMessage = 3
+	protoFieldAttrs   = 4
+	protoFieldKey     = 1
+	protoFieldValue   = 2
+)
+
+// EncodeProtoRecord is the default ProtoEncoder. It writes the record as a
+// protobuf message prefixed with its varint length, like Java's writeDelimitedTo.
+func EncodeProtoRecord(level slog.Level, t time.Time, msg string, attrs []slog.Attr) []byte {
+	var message []byte
+	if !t.IsZero() {
+		message = appendProtoVarint(message, protoFieldTime, uint64(t.UnixNano()))
+	}
+	message = appendProtoVarint(message, protoFieldLevel, zigzag(int64(level)))
+	message = appendProtoBytes(message, protoFieldMessage, []byte(msg))
+	for _, attr := range flattenAttrs("", attrs) {
+		var pair []byte
+		pair = appendProtoBytes(pair, protoFieldKey, []byte(attr.Key))
+		pair = appendProtoBytes(pair, protoFieldValue, []byte(logfmtValue(attr.Value)))
+		message = appendProtoBytes(message, protoFieldAttrs, pair)
+	}
+
+	return append(binary.AppendUvarint(nil, uint64(len(message))), message...)
+}
+
+// DecodeProtoRecords decodes length-prefixed records written by EncodeProtoRecord.
+// Attribute values are strings keyed by their dotted path.
+func DecodeProtoRecords(data []byte) ([]Record, error) {
+	var records []Record
+	for len(data) > 0 {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return records, errors.New("truncated protobuf record")
+		}
+		record, err := decodeProtoRecord(data[n : n+int(size)])
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+		data = data[n+int(size):]
+	}
+	return records, nil
+}
+
+// decodeProtoRecord decodes one Record message
+func decodeProtoRecord(message []byte) (Record, error) {
+	record := Record{Attrs: make(map[string]any)}
+	err := decodeProtoFields(message, func(field uint64, varint uint64, bytes []byte) error {
+		switch field {
+		case protoFieldTime:
+			record.Time = time.Unix(0, int64(varint))
+		case protoFieldLevel:
+			record.Level = slog.Level(unzigzag(varint))
+		case protoFieldMessage:
+			record.Message = string(bytes)
+		case protoFieldAttrs:
+			var key, value string
+			err := decodeProtoFields(bytes, func(field uint64, _ uint64, bytes []byte) error {
+				switch field {
+				case protoFieldKey:
+					key = string(bytes)
+				case protoFieldValue:
+					value = string(bytes)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			record.Attrs[key] = value
+		}
+		return nil
+	})
+	return record, err
+}
+
+// decodeProtoFields calls fn for each varint or length-delimited field of a message
+func decodeProtoFields(message []byte, fn func(field uint64, varint uint64, bytes []byte) error) error {
+	for len(message) > 0 {
+		tag, n := binary.Uvarint(message)
+		if n <= 0 {
+			return errors.New("invalid protobuf tag")
+		}
+		message = message[n:]
+
+		value, n := binary.Uvarint(message)
+		if n <= 0 {
+			return errors.New("invalid protobuf varint")
+		}
+		message = message[n:]
+
+		switch tag & 7 {
+		case protoVarint:
+			if err := fn(tag>>3, value, nil); err != nil {
+				return err
+			}
+		case protoBytes:
+			if uint64(len(message)) < value {
+				return errors.New("truncated protobuf field")
+			}
+			if err := fn(tag>>3, 0, message[:value]); err != nil {
+				return err
+			}
+			message = message[value:]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", tag&7)
+		}
+	}
+	return nil
+}
+
+// appendProtoVarint appends a varint field
+func appendProtoVarint(buf []byte, field int, value uint64) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field)<<3|protoVarint)
+	return binary.AppendUvarint(buf, value)
+}
+
+// appendProtoBytes appends a length-delimited field
+func appendProtoBytes(buf []byte, field int, value []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field)<<3|protoBytes)
+	buf = binary.AppendUvarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+// zigzag maps signed values to unsigned ones so small negatives stay short
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// unzigzag reverses zigzag
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// flattenAttrs resolves the attributes and flattens groups into dotted keys
+func flattenAttrs(prefix string, attrs []slog.Attr) []slog.Attr {
+	var flat []slog.Attr
+	for _, attr := range attrs {
+		attr.Value = attr.Value.Resolve()
+		if attr.Value.Kind() != slog.KindGroup {
+			flat = append(flat, slog.Attr{Key: prefix + attr.Key, Value: attr.Value})
+			continue
+		}
+		nested := prefix
+		if attr.Key != "" {
+			nested = prefix + attr.Key + "."
+		}
+		flat = append(flat, flattenAttrs(nested, attr.Value.Group())...)
+	}
+	return flat
+}
+
+// binaryHandler encodes each record with a ProtoEncoder and writes it to an io.Writer
+type binaryHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	encode ProtoEncoder
+	level  slog.Leveler
+	attrs  []slog.Attr // Bound attributes, already nested under the groups open when they were added
+	groups []string    // Groups opened since, wrapping new attributes
+}
+
+// newBinaryHandler creates a binary handler, using EncodeProtoRecord if encode is nil
+func newBinaryHandler(w io.Writer, encode ProtoEncoder, level slog.Leveler) *binaryHandler {
+	if encode == nil {
+		encode = EncodeProtoRecord
+	}
+	return &binaryHandler{w: w, mu: &sync.Mutex{}, encode: encode, level: level}
+}
+
+// Enabled reports whether the level reaches the configured minimum
+func (h *binaryHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle encodes the record and writes it in a single call
+func (h *binaryHandler) Handle(ctx context.Context, record slog.Record) error {
+	var own []slog.Attr
+	record.Attrs(func(attr slog.Attr) bool {
+		own = append(own, attr)
+		return true
+	})
+
+	data := h.encode(record.Level, record.Time, record.Message, append(slices.Clip(h.attrs), h.nest(own)...))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(data)
+	return err
+}
+
+// nest wraps attributes in the open groups
+func (h *binaryHandler) nest(attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	for _, group := range slices.Backward(h.groups) {
+		attrs = []slog.Attr{{Key: group, Value: slog.GroupValue(attrs...)}}
+	}
+	return attrs
+}
+
+// WithAttrs creates a new handler with additional bound attributes
+func (h *binaryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(slices.Clip(h.attrs), h.nest(attrs)...)
+	return &clone
+}
+
+// WithGroup creates a new handler that nests new attributes under the group name
+func (h *binaryHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(slices.Clip(h.groups), name)
+	return &clone
+}
@@ -0,0 +1,79 @@
+package iSlogger
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestBinaryWriter_ProtoRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	var calls int
+	encode := func(level slog.Level, tm time.Time, msg string, attrs []slog.Attr) []byte {
+		calls++
+		return EncodeProtoRecord(level, tm, msg, attrs)
+	}
+
+	config := DefaultConfig().
+		WithAppName("test-proto").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithLogLevel(slog.LevelDebug).
+		WithBinaryWriter(&buf).
+		WithProtoEncoder(encode)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Debug("cache miss", "key", "user:1")
+	logger.With("service", "api").Info("order placed", "amount", 42)
+	logger.Error("payment failed", slog.Group("db", "table", "payments"))
+
+	if calls != 3 {
+		t.Errorf("Expected the encoder to run once per record, got %d calls", calls)
+	}
+
+	records, err := DecodeProtoRecords(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to decode records: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+
+	expected := []struct {
+		level slog.Level
+		msg   string
+		attrs map[string]any
+	}{
+		{slog.LevelDebug, "cache miss", map[string]any{"key": "user:1"}},
+		{slog.LevelInfo, "order placed", map[string]any{"service": "api", "amount": "42"}},
+		{slog.LevelError, "payment failed", map[string]any{"db.table": "payments"}},
+	}
+	for i, want := range expected {
+		got := records[i]
+		if got.Level != want.level || got.Message != want.msg || got.Time.IsZero() || len(got.Attrs) != len(want.attrs) {
+			t.Errorf("Record %d: expected %s %q %v, got %+v", i, want.level, want.msg, want.attrs, got)
+			continue
+		}
+		for key, value := range want.attrs {
+			if got.Attrs[key] != value {
+				t.Errorf("Record %d: expected %s=%v, got %v", i, key, value, got.Attrs[key])
+			}
+		}
+	}
+}
+
+func TestDecodeProtoRecords_Truncated(t *testing.T) {
+	data := EncodeProtoRecord(slog.LevelWarn, time.Now(), "slow query", []slog.Attr{slog.Int("ms", 950)})
+	if _, err := DecodeProtoRecords(data[:len(data)-1]); err == nil {
+		t.Error("Expected an error decoding a truncated record")
+	}
+	if _, err := DecodeProtoRecords(bytes.Repeat(data, 2)); err != nil {
+		t.Errorf("Expected consecutive records to decode, got %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+package iSlogger
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rateLimiter enforces the configured rate limits. It is built once from the
+// config and shared by all handlers, so limits survive file rotation.
+type rateLimiter struct {
+	global       *rateWindow
+	bypassErrors bool
+	messages     *messageLimiter
+	sampler      *adaptiveSampler
+	levels       map[slog.Level]limit // Read-only after construction
+}
+
+// limit decides whether one more record fits at time now (Unix nanoseconds)
+type limit interface {
+	allow(now int64) bool
+	status(now int64) RateLimitStatus
+	reset()
+}
+
+// RateLimitStatus reports the budget of a level's rate limit
+type RateLimitStatus struct {
+	Used      int       // Records counted in the current window, or tokens taken from the bucket
+	Remaining int       // Records that may still be written right now
+	Reset     time.Time // When the full budget is available again (zero if it already is)
+}
+
+// RateLimitStatus returns the current budget of every level with a rate limit
+// or token bucket, or nil if there are none
+func (l *Logger) RateLimitStatus() map[slog.Level]RateLimitStatus {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.limiter == nil || len(l.limiter.levels) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixNano()
+	statuses := make(map[slog.Level]RateLimitStatus, len(l.limiter.levels))
+	for level, levelLimit := range l.limiter.levels {
+		statuses[level] = levelLimit.status(now)
+	}
+	return statuses
+}
+
+// ResetRateLimits restores the full budget of every rate limit, e.g. after a
+// deploy. Global and per-message limits and adaptive sampling start over too.
+func (l *Logger) ResetRateLimits() {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	l.limiter.reset()
+}
+
+// reset restores the full budget of every limit
+func (rl *rateLimiter) reset() {
+	if rl == nil {
+		return
+	}
+	if rl.global != nil {
+		rl.global.reset()
+	}
+	if rl.messages != nil {
+		rl.messages.windows.Clear()
+	}
+	if rl.sampler != nil {
+		rl.sampler.reset()
+	}
+	for _, levelLimit := range rl.levels {
+		levelLimit.reset()
+	}
+}
+
+// rateWindow counts records within a fixed time window
+type rateWindow struct {
+	maxCount int64
+	period   int64        // Window length in nanoseconds
+	start    atomic.Int64 // Window start in Unix nanoseconds
+	count    atomic.Int64
+	mu       sync.Mutex // Serializes window resets
+}
+
+// newRateLimiter creates a rate limiter for the filter config, or nil if no limits are set
+func newRateLimiter(fc FilterConfig) *rateLimiter {
+	hasGlobal := fc.GlobalRateLimit.MaxCount > 0
+	hasMessage := fc.MessageRateLimit.MaxCount > 0
+	hasSampling := fc.SamplingTarget > 0
+	if len(fc.RateLimits) == 0 && len(fc.TokenBuckets) == 0 && !hasGlobal && !hasMessage && !hasSampling {
+		return nil
+	}
+
+	rl := &rateLimiter{levels: make(map[slog.Level]limit, len(fc.RateLimits)+len(fc.TokenBuckets))}
+	if hasGlobal {
+		rl.global = &rateWindow{
+			maxCount: int64(fc.GlobalRateLimit.MaxCount),
+			period:   int64(fc.GlobalRateLimit.Period),
+		}
+		rl.bypassErrors = fc.GlobalBypassErrors
+	}
+	if hasMessage {
+		rl.messages = &messageLimiter{
+			maxCount: int64(fc.MessageRateLimit.MaxCount),
+			period:   int64(fc.MessageRateLimit.Period),
+		}
+	}
+	if hasSampling {
+		rl.sampler = &adaptiveSampler{target: fc.SamplingTarget, probability: 1}
+	}
+	for level, rateLimit := range fc.RateLimits {
+		rl.levels[level] = &rateWindow{
+			maxCount: int64(rateLimit.MaxCount),
+			period:   int64(rateLimit.Period),
+		}
+	}
+	for level, bucket := range fc.TokenBuckets {
+		rl.levels[level] = &tokenBucket{
+			rate:   bucket.Rate,
+			burst:  float64(bucket.Burst),
+			tokens: float64(bucket.Burst),
+		}
+	}
+	return rl
+}
+
+// allow reports whether a record with the given level and message may be written.
+// Limits measure real throughput, so windows follow the real clock rather than
+// Config.Clock, which may stand still in tests.
+func (rl *rateLimiter) allow(level slog.Level, msg string) bool {
+	if rl == nil {
+		return true
+	}
+
+	now := time.Now().UnixNano()
+
+	// Floods of low-priority records are thinned out before they reach any limit
+	if rl.sampler != nil && level < slog.LevelWarn && !rl.sampler.allow(now) {
+		return false
+	}
+
+	// The global limit applies first so it caps the total across levels
+	if rl.global != nil && !(rl.bypassErrors && level >= slog.LevelError) {
+		if !rl.global.allow(now) {
+			return false
+		}
+	}
+
+	// A repeating message is cut off before it uses up the level's budget
+	if rl.messages != nil && !rl.messages.allow(msg, now) {
+		return false
+	}
+
+	levelLimit, exists := rl.levels[level]
+	if !exists {
+		return true // No rate limit set, allow
+	}
+	return levelLimit.allow(now)
+}
+
+// allow counts one record in the current window, starting a new window when the period has passed
+func (w *rateWindow) allow(now int64) bool {
+	if now-w.start.Load() >= w.period {
+		w.mu.Lock()
+		// Another goroutine may have started the window already
+		if now-w.start.Load() >= w.period {
+			w.count.Store(0)
+			w.start.Store(now)
+		}
+		w.mu.Unlock()
+	}
+
+	return w.count.Add(1) <= w.maxCount
+}
+
+// status reports the records counted in the current window
+func (w *rateWindow) status(now int64) RateLimitStatus {
+	start := w.start.Load()
+	if now-start >= w.period {
+		return RateLimitStatus{Remaining: int(w.maxCount)} // The next record starts a new window
+	}
+	used := min(w.count.Load(), w.maxCount)
+	return RateLimitStatus{
+		Used:      int(used),
+		Remaining: int(w.maxCount - used),
+		Reset:     time.Unix(0, start+w.period),
+	}
+}
+
+// reset ends the current window, so the next record starts a new one
+func (w *rateWindow) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.start.Store(0)
+	w.count.Store(0)
+}
+
+// messageLimiter gives every distinct message its own rate window
+type messageLimiter struct {
+	maxCount  int64
+	period    int64
+	windows   sync.Map     // Message to *rateWindow
+	lastSweep atomic.Int64 // Last stale window sweep in Unix nanoseconds
+}
+
+// allow counts one record in the message's window
+func (m *messageLimiter) allow(msg string, now int64) bool {
+	if last := m.lastSweep.Load(); now-last >= m.period && m.lastSweep.CompareAndSwap(last, now) {
+		m.sweep(now)
+	}
+
+	w, ok := m.windows.Load(msg)
+	if !ok {
+		w, _ = m.windows.LoadOrStore(msg, &rateWindow{maxCount: m.maxCount, period: m.period})
+	}
+	return w.(*rateWindow).allow(now)
+}
+
+// sweep forgets messages whose window has expired so the map doesn't grow without bound
+func (m *messageLimiter) sweep(now int64) {
+	m.windows.Range(func(key, value any) bool {
+		if now-value.(*rateWindow).start.Load() >= m.period {
+			m.windows.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
+
+// samplingTick is how often the adaptive sampler re-measures the incoming rate
+const samplingTick = 100 * time.Millisecond
+
+// adaptiveSampler keeps roughly target records per second. Records are kept with a
+// probability adjusted every tick to the measured incoming rate, and each second is
+// capped at target so bursts faster than the adjustment are still bounded.
+type adaptiveSampler struct {
+	mu          sync.Mutex
+	target      int
+	probability float64 // Chance of keeping a record, 1 below the target rate
+	tickStart   int64   // Current measurement tick in Unix nanoseconds
+	tickSeen    int     // Records seen in the current tick
+	secondStart int64   // Current one-second window in Unix nanoseconds
+	secondKept  int     // Records kept in the current window
+}
+
+// allow counts the record and decides whether it is kept
+func (s *adaptiveSampler) allow(now int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elapsed := now - s.tickStart; elapsed >= 2*int64(samplingTick) {
+		// Nothing arrived for a whole tick, so the burst is over
+		s.probability = 1
+		s.tickStart, s.tickSeen = now, 0
+	} else if elapsed >= int64(samplingTick) {
+		rate := float64(s.tickSeen) * float64(time.Second) / float64(elapsed)
+		s.probability = min(1, float64(s.target)/rate)
+		s.tickStart, s.tickSeen = now, 0
+	}
+	if now-s.secondStart >= int64(time.Second) {
+		s.secondStart, s.secondKept = now, 0
+	}
+	s.tickSeen++
+
+	if s.secondKept >= s.target || rand.Float64() >= s.probability {
+		return false
+	}
+	s.secondKept++
+	return true
+}
+
+// reset forgets the measured rate, keeping every record until it is measured again
+func (s *adaptiveSampler) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.probability = 1
+	s.tickStart, s.tickSeen = 0, 0
+	s.secondStart, s.secondKept = 0, 0
+}
+
+// tokenBucket refills continuously so records are shaped smoothly instead of cut off per window
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens per second
+	burst  float64
+	tokens float64
+	last   int64 // Last refill in Unix nanoseconds
+}
+
+// allow refills the bucket for the elapsed time and takes one token if available
+func (b *tokenBucket) allow(now int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.last != 0 {
+		elapsed := float64(now-b.last) / float64(time.Second)
+		b.tokens = min(b.burst, b.tokens+elapsed*b.rate)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// status reports the tokens available at now, without taking any
+func (b *tokenBucket) status(now int64) RateLimitStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tokens := b.tokens
+	if b.last != 0 {
+		tokens = min(b.burst, tokens+float64(now-b.last)/float64(time.Second)*b.rate)
+	}
+	status := RateLimitStatus{Remaining: int(tokens)}
+	status.Used = int(b.burst) - status.Remaining
+	if tokens < b.burst {
+		refill := time.Duration((b.burst - tokens) / b.rate * float64(time.Second))
+		status.Reset = time.Unix(0, now).Add(refill)
+	}
+	return status
+}
+
+// reset refills the bucket
+func (b *tokenBucket) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = b.burst
+	b.last = 0
+}
@@ -0,0 +1,320 @@
+package iSlogger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Record is a log record read back from a JSON log file
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   map[string]any // Every other key, groups as nested maps
+}
+
+// ReadOptions filters the records returned by ReadLogs
+type ReadOptions struct {
+	MinLevel slog.Level     // Skip records below this level (zero value = INFO)
+	Attrs    map[string]any // Keep records whose attributes equal these values; dotted keys reach into groups
+}
+
+// ReadLogs returns the records written on the given date, oldest first, from LogDir
+// and BackupDir. It only works with JSON files; lines that aren't records, such as a
+// file header or a line cut short by a crash, are skipped.
+func (l *Logger) ReadLogs(date time.Time, opts ReadOptions) ([]Record, error) {
+	if l.nop {
+		return nil, nil
+	}
+
+	config := l.currentConfig()
+	if config.fileFormat() != FormatJSON {
+		return nil, fmt.Errorf("reading logs requires JSON format, got %s", config.fileFormat())
+	}
+
+	// Make buffered records visible
+	if err := l.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to flush before reading: %w", err)
+	}
+
+	day := date.Format("2006-01-02")
+	var records []Record
+	for _, kind := range config.fileKinds() {
+		// In per-level mode the error file may duplicate WARN records from the warn file
+		minLevel := opts.MinLevel
+		if kind == "error" && config.PerLevelFiles {
+			minLevel = max(minLevel, slog.LevelError)
+		}
+
+		for _, dir := range config.logDirs() {
+			read, err := config.readLogFile(filepath.Join(dir, config.logFileName(kind, day)), minLevel, opts.Attrs)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, read...)
+		}
+	}
+
+	slices.SortStableFunc(records, func(a, b Record) int {
+		return a.Time.Compare(b.Time)
+	})
+	return records, nil
+}
+
+// fileKinds returns the kinds of file written per day, as passed to logFileName
+func (c Config) fileKinds() []string {
+	switch {
+	case c.PerLevelFiles:
+		return levelKinds[:]
+	case c.CombinedFile:
+		return []string{""}
+	default:
+		return []string{"", "error"}
+	}
+}
+
+// readLogFile decodes the matching records of one file. A missing file has no records.
+func (c Config) readLogFile(path string, minLevel slog.Level, attrs map[string]any) ([]Record, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		record, ok := c.decodeRecord(scanner.Bytes())
+		if !ok || record.Level < minLevel || !record.matches(attrs) {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log file %s: %w", filepath.Base(path), err)
+	}
+	return records, nil
+}
+
+// decodeRecord parses one JSON line, reporting false if it isn't a log record
+func (c Config) decodeRecord(line []byte) (Record, bool) {
+	var fields map[string]any
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return Record{}, false
+	}
+
+	timeKey, levelKey, messageKey := c.builtinKey(slog.TimeKey), c.builtinKey(slog.LevelKey), c.builtinKey(slog.MessageKey)
+	levelName, hasLevel := fields[levelKey].(string)
+	message, hasMessage := fields[messageKey].(string)
+	if !hasLevel || !hasMessage {
+		return Record{}, false
+	}
+	level, ok := c.parseLevel(levelName)
+	if !ok {
+		return Record{}, false
+	}
+
+	record := Record{Level: level, Message: message}
+	if value, ok := fields[timeKey].(string); ok {
+		record.Time = c.parseTime(value)
+	}
+
+	delete(fields, timeKey)
+	delete(fields, levelKey)
+	delete(fields, messageKey)
+	record.Attrs = fields
+	return record, true
+}
+
+// parseLevel reverses the level names written to files, custom LevelNames included
+func (c Config) parseLevel(name string) (slog.Level, bool) {
+	for level, custom := range c.LevelNames {
+		if custom == name {
+			return level, true
+		}
+	}
+	var level slog.Level
+	return level, level.UnmarshalText([]byte(name)) == nil
+}
+
+// parseTime parses a record time in the configured format, falling back to RFC 3339
+func (c Config) parseTime(value string) time.Time {
+	if t, err := time.Parse(c.TimeFormat, value); err == nil {
+		return t
+	}
+	t, _ := time.Parse(time.RFC3339Nano, value)
+	return t
+}
+
+// matches reports whether every wanted attribute has the given value.
+// Values are compared by their printed form, so 42 matches a decoded 42.0.
+func (r Record) matches(attrs map[string]any) bool {
+	for key, want := range attrs {
+		got, ok := lookupAttr(r.Attrs, key)
+		if !ok || fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupAttr finds a key, following dots into nested groups when there is no exact match
+func lookupAttr(attrs map[string]any, key string) (any, bool) {
+	if value, ok := attrs[key]; ok {
+		return value, true
+	}
+	group, rest, found := strings.Cut(key, ".")
+	if !found {
+		return nil, false
+	}
+	nested, ok := attrs[group].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return lookupAttr(nested, rest)
+}
+
+// followPollInterval is how often FollowLogs checks the files for new data
+const followPollInterval = 100 * time.Millisecond
+
+// FollowLogs streams records appended to today's JSON files from now on, switching
+// to the new files at midnight, until ctx is cancelled and the channel is closed.
+// Buffered records arrive once they are flushed.
+func (l *Logger) FollowLogs(ctx context.Context) (<-chan Record, error) {
+	records := make(chan Record, 64)
+	if l.nop {
+		close(records)
+		return records, nil
+	}
+
+	config := l.currentConfig()
+	if config.fileFormat() != FormatJSON {
+		return nil, fmt.Errorf("following logs requires JSON format, got %s", config.fileFormat())
+	}
+
+	f := &follower{config: config, date: config.now().Format("2006-01-02"), offsets: make(map[string]int64)}
+	for _, kind := range config.fileKinds() {
+		name := config.logFileName(kind, f.date)
+		for _, dir := range config.logDirs() {
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				f.offsets[name] = info.Size() // Only records written from now on
+			}
+		}
+	}
+
+	go f.run(ctx, records)
+	return records, nil
+}
+
+// follower tracks how far each of the current day's files has been read. Offsets
+// are kept by file name, so a file moved to BackupDir continues where it left off.
+type follower struct {
+	config  Config
+	date    string
+	offsets map[string]int64
+}
+
+// run polls the files until ctx is cancelled
+func (f *follower) run(ctx context.Context, out chan<- Record) {
+	defer close(out)
+
+	ticker := time.NewTicker(followPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !f.send(ctx, out, f.poll()) {
+			return
+		}
+
+		// After midnight, finish the old files and start the new ones from the top
+		if today := f.config.now().Format("2006-01-02"); today != f.date {
+			if !f.send(ctx, out, f.poll()) {
+				return
+			}
+			f.date = today
+			clear(f.offsets)
+		}
+	}
+}
+
+// send delivers the records, reporting false if ctx was cancelled first
+func (f *follower) send(ctx context.Context, out chan<- Record, records []Record) bool {
+	for _, record := range records {
+		select {
+		case out <- record:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// poll reads the complete lines appended to each file since the last poll
+func (f *follower) poll() []Record {
+	var records []Record
+	for _, kind := range f.config.fileKinds() {
+		name := f.config.logFileName(kind, f.date)
+		for _, dir := range f.config.logDirs() {
+			records = append(records, f.readNew(kind, filepath.Join(dir, name))...)
+		}
+	}
+	slices.SortStableFunc(records, func(a, b Record) int {
+		return a.Time.Compare(b.Time)
+	})
+	return records
+}
+
+// readNew decodes the complete lines after the file's offset and advances it
+func (f *follower) readNew(kind, path string) []Record {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	name := filepath.Base(path)
+	info, err := file.Stat()
+	if err != nil || info.Size() <= f.offsets[name] {
+		return nil
+	}
+	data := make([]byte, info.Size()-f.offsets[name])
+	if _, err := file.ReadAt(data, f.offsets[name]); err != nil {
+		return nil
+	}
+
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil // Wait for the rest of the line
+	}
+	f.offsets[name] += int64(end + 1)
+
+	var records []Record
+	for line := range bytes.Lines(data[:end+1]) {
+		record, ok := f.config.decodeRecord(line)
+		// In per-level mode the error file may duplicate WARN records from the warn file
+		if !ok || (kind == "error" && f.config.PerLevelFiles && record.Level < slog.LevelError) {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
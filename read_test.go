@@ -0,0 +1,146 @@
+package iSlogger
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadLogs(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-read").
+		WithLogDir(t.TempDir()).
+		WithLogLevel(slog.LevelDebug).
+		WithConsoleOutput(false).
+		WithJSONFormat(true).
+		WithTimeFormat(time.RFC3339Nano)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Debug("cache miss", "user", "alice")
+	logger.Info("order placed", "user", "alice", "amount", 42)
+	logger.Warn("slow query", "user", "bob", slog.Group("db", "table", "orders"))
+	logger.Error("payment failed", "user", "alice", slog.Group("db", "table", "payments"))
+
+	all, err := logger.ReadLogs(time.Now(), ReadOptions{MinLevel: slog.LevelDebug})
+	if err != nil {
+		t.Fatalf("ReadLogs failed: %v", err)
+	}
+	var messages []string
+	for _, record := range all {
+		messages = append(messages, record.Message)
+	}
+	if len(messages) != 4 || messages[0] != "cache miss" || messages[3] != "payment failed" {
+		t.Fatalf("Expected all four records in order, got %v", messages)
+	}
+	if all[1].Level != slog.LevelInfo || all[1].Attrs["amount"] != float64(42) || all[1].Time.IsZero() {
+		t.Errorf("Expected the decoded INFO record, got %+v", all[1])
+	}
+
+	tests := []struct {
+		name     string
+		opts     ReadOptions
+		expected []string
+	}{
+		{"min level", ReadOptions{MinLevel: slog.LevelWarn}, []string{"slow query", "payment failed"}},
+		{"attribute", ReadOptions{Attrs: map[string]any{"user": "alice"}}, []string{"order placed", "payment failed"}},
+		{"numeric attribute", ReadOptions{Attrs: map[string]any{"amount": 42}}, []string{"order placed"}},
+		{"grouped attribute", ReadOptions{Attrs: map[string]any{"db.table": "orders"}}, []string{"slow query"}},
+		{"no match", ReadOptions{Attrs: map[string]any{"user": "carol"}}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			records, err := logger.ReadLogs(time.Now(), test.opts)
+			if err != nil {
+				t.Fatalf("ReadLogs failed: %v", err)
+			}
+			var got []string
+			for _, record := range records {
+				got = append(got, record.Message)
+			}
+			if len(got) != len(test.expected) {
+				t.Fatalf("Expected %v, got %v", test.expected, got)
+			}
+			for i := range got {
+				if got[i] != test.expected[i] {
+					t.Errorf("Expected %v, got %v", test.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestReadLogs_RequiresJSON(t *testing.T) {
+	logger, err := New(DefaultConfig().WithAppName("test-read-text").WithLogDir(t.TempDir()).WithConsoleOutput(false))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	if _, err := logger.ReadLogs(time.Now(), ReadOptions{}); err == nil {
+		t.Error("Expected an error reading text format logs")
+	}
+}
+
+func TestFollowLogs(t *testing.T) {
+	var now atomic.Int64
+	now.Store(time.Date(2024, 3, 9, 23, 0, 0, 0, time.Local).UnixNano())
+
+	config := DefaultConfig().
+		WithAppName("test-follow").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithJSONFormat(true).
+		WithClock(func() time.Time { return time.Unix(0, now.Load()) })
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Info("before follow")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	records, err := logger.FollowLogs(ctx)
+	if err != nil {
+		t.Fatalf("FollowLogs failed: %v", err)
+	}
+
+	receive := func(expected ...string) {
+		t.Helper()
+		for _, msg := range expected {
+			select {
+			case record := <-records:
+				if record.Message != msg {
+					t.Fatalf("Expected %q, got %q", msg, record.Message)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Timed out waiting for %q", msg)
+			}
+		}
+	}
+
+	logger.Info("first")
+	logger.Warn("second", "user", "alice")
+	receive("first", "second")
+
+	// Past midnight the logger rotates on the next record and the follower switches files
+	logger.Info("last of the day")
+	now.Add(int64(2 * time.Hour))
+	logger.Info("after midnight")
+	receive("last of the day", "after midnight")
+
+	cancel()
+	for range records {
+		// Drain until the channel is closed
+	}
+}
@@ -0,0 +1,39 @@
+package iSlogger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// startSignalReload reloads the config file on every SIGHUP until the logger is closed
+func (l *Logger) startSignalReload(path string) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
+
+	for {
+		select {
+		case <-sigChan:
+			l.reloadConfig(path)
+		case <-l.done:
+			return
+		}
+	}
+}
+
+// reloadConfig applies the config file on top of the current config.
+// Invalid files are logged and the current config is kept.
+func (l *Logger) reloadConfig(path string) error {
+	config, err := loadConfigFile(l.currentConfig(), path)
+	if err == nil {
+		err = l.Reconfigure(config)
+	}
+	if err != nil {
+		l.Error("Failed to reload configuration", "path", path, "error", err)
+		return err
+	}
+
+	l.Info("Configuration reloaded", "path", path)
+	return nil
+}
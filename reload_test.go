@@ -0,0 +1,100 @@
+package iSlogger
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"app_name": "loaded", "log_level": "debug", "json_format": true, "flush_interval": "2s"}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.AppName != "loaded" {
+		t.Errorf("Expected app name 'loaded', got '%s'", config.AppName)
+	}
+	if config.LogLevel != slog.LevelDebug {
+		t.Errorf("Expected DEBUG level, got %v", config.LogLevel)
+	}
+	if !config.JSONFormat {
+		t.Error("Expected JSON format to be enabled")
+	}
+	if config.FlushInterval != 2*time.Second {
+		t.Errorf("Expected flush interval 2s, got %v", config.FlushInterval)
+	}
+	if config.RetentionDays != DefaultConfig().RetentionDays {
+		t.Errorf("Expected default retention days, got %d", config.RetentionDays)
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"unknown_field.json":  `{"unknown": true}`,
+		"bad_level.json":      `{"log_level": "loud"}`,
+		"bad_interval.json":   `{"flush_interval": "soon"}`,
+		"negative_size.json":  `{"buffer_size": -1}`,
+		"malformed_json.json": `{"log_level": `,
+	}
+
+	for name, data := range tests {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("Expected error loading %s", name)
+		}
+	}
+}
+
+func TestSignalReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reload.json")
+	if err := os.WriteFile(path, []byte(`{"log_level": "warn"}`), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config := DefaultConfig().
+		WithAppName("test-reload").
+		WithLogDir("test-logs-reload").
+		WithConsoleOutput(false).
+		WithSignalReload(path)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-reload")
+
+	if err := logger.reloadConfig(path); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if logger.config.LogLevel != slog.LevelWarn {
+		t.Errorf("Expected WARN level after reload, got %v", logger.config.LogLevel)
+	}
+
+	// Invalid reloads keep the current config
+	if err := os.WriteFile(path, []byte(`{"log_level": "loud"}`), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := logger.reloadConfig(path); err == nil {
+		t.Error("Expected error reloading invalid config")
+	}
+	if logger.config.LogLevel != slog.LevelWarn {
+		t.Errorf("Expected WARN level to be kept, got %v", logger.config.LogLevel)
+	}
+	if logger.config.ReloadPath != path {
+		t.Errorf("Expected reload path to be kept, got '%s'", logger.config.ReloadPath)
+	}
+}
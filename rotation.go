@@ -1,68 +1,375 @@
 package iSlogger
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 )
 
+// minCleanupInterval guards against busy-looping on tiny cleanup intervals
+const minCleanupInterval = 100 * time.Millisecond
+
+// maxRotationWait bounds how long the rotation timer sleeps between date checks,
+// so clock jumps and suspended machines are noticed without waiting a full day
+var maxRotationWait = time.Minute
+
+// startRotationTimer rotates files at midnight even when nothing is being logged
+func (l *Logger) startRotationTimer(maxWait time.Duration) {
+	timer := time.NewTimer(l.untilNextRotation(maxWait))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			l.checkDateRotation()
+			timer.Reset(l.untilNextRotation(maxWait))
+		case <-l.done:
+			return
+		}
+	}
+}
+
+// untilNextRotation returns the wait until the next local midnight, capped at maxWait
+func (l *Logger) untilNextRotation(maxWait time.Duration) time.Duration {
+	now := l.currentConfig().now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	return min(midnight.Sub(now), maxWait)
+}
+
+// reportRotationLocked calls the rotation hook with each previous file and the open
+// file that replaced it, matched by kind (info, error, ...). The hook runs on its own
+// goroutine, so it may log without deadlocking on the lock held here.
+func (l *Logger) reportRotationLocked(previous []string, previousDate, date string) {
+	newPaths := make(map[string]string, len(l.files))
+	for _, lf := range l.files {
+		newPaths[strings.Replace(filepath.Base(lf.path), "_"+date, "", 1)] = lf.path
+	}
+
+	type rotation struct{ oldPath, newPath string }
+	var rotations []rotation
+	for _, oldPath := range previous {
+		kind := strings.Replace(filepath.Base(oldPath), "_"+previousDate, "", 1)
+		if newPath, ok := newPaths[kind]; ok {
+			rotations = append(rotations, rotation{oldPath, newPath})
+		}
+	}
+
+	hook := l.config.RotationHook
+	go func() {
+		for _, r := range rotations {
+			hook(r.oldPath, r.newPath)
+		}
+	}()
+}
+
+// archiveFilesLocked moves rotated files into BackupDir (must be called with lock held).
+// A file that cannot be moved stays in LogDir, where cleanup still finds it.
+// Each entry of paths is updated to where its file ended up.
+func (l *Logger) archiveFilesLocked(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+
+	var errs []error
+	if err := l.config.makeDir(l.config.BackupDir); err != nil {
+		errs = append(errs, fmt.Errorf("failed to create backup directory: %w", err))
+	} else {
+		for i, path := range paths {
+			dst := filepath.Join(l.config.BackupDir, filepath.Base(path))
+			if err := moveFile(path, dst); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			paths[i] = dst
+		}
+	}
+
+	if len(errs) > 0 {
+		// Logging takes the lock, so the record is handled once it has been released.
+		// It is built here to be attributed to this function, not the new goroutine.
+		var pcs [1]uintptr
+		runtime.Callers(1, pcs[:]) // skip [runtime.Callers]
+		record := slog.NewRecord(l.config.now(), slog.LevelError, "Failed to move rotated log files", pcs[0])
+		record.AddAttrs(slog.Any("error", errors.Join(errs...)))
+		go l.handle(context.Background(), record)
+	}
+}
+
+// moveFile renames src to dst, copying across filesystems where a rename is not possible
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to move %s: %w", filepath.Base(src), err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to move %s: %w", filepath.Base(src), err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to move %s: %w", filepath.Base(src), err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to move %s: %w", filepath.Base(src), err)
+	}
+	return os.Remove(src)
+}
+
 // startCleanupRoutine starts the cleanup goroutine
 func (l *Logger) startCleanupRoutine() {
-	ticker := time.NewTicker(24 * time.Hour)
+	interval := l.currentConfig().CleanupInterval
+	if interval < minCleanupInterval {
+		interval = minCleanupInterval
+	}
+
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	l.performCleanup()
 
-	//lint:ignore S1000 more idiomatic for select with multiple cases
 	for {
 		select {
 		case <-ticker.C:
 			l.performCleanup()
+		case <-l.done:
+			return
 		}
 	}
 }
 
-// performCleanup removes old log files
+// cleanupResult collects the outcome of a cleanup run
+type cleanupResult struct {
+	dryRun  bool
+	removed []string
+	errs    []error
+}
+
+// performCleanup removes old log files in the background
 func (l *Logger) performCleanup() {
-	cutoffDate := time.Now().AddDate(0, 0, -l.config.RetentionDays)
+	if _, err := l.Cleanup(); err != nil && l.errorLogger != nil {
+		l.Error("Log cleanup failed", "error", err)
+	}
+}
 
-	entries, err := os.ReadDir(l.config.LogDir)
-	if err != nil {
-		if l.errorLogger != nil {
-			l.Error("Failed to read log directory", "error", err)
+// Cleanup synchronously removes old log files according to the retention settings.
+// It returns the names of the removed files and any errors encountered.
+func (l *Logger) Cleanup() (removed []string, err error) {
+	return l.cleanup(false)
+}
+
+// CleanupDryRun returns the names of the log files Cleanup would remove
+// without deleting anything.
+func (l *Logger) CleanupDryRun() ([]string, error) {
+	return l.cleanup(true)
+}
+
+// cleanup applies the retention settings, only collecting candidates in dry-run mode
+func (l *Logger) cleanup(dryRun bool) ([]string, error) {
+	if l.nop {
+		return nil, nil
+	}
+
+	config := l.currentConfig()
+	cutoffDate := config.now().AddDate(0, 0, -config.RetentionDays)
+
+	result := &cleanupResult{dryRun: dryRun}
+
+	var kept []dirFile
+	for _, dir := range config.logDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if dir == config.BackupDir && os.IsNotExist(err) {
+				continue // Nothing has rotated yet
+			}
+			return nil, fmt.Errorf("failed to read log directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || entry.Type()&fs.ModeSymlink != 0 {
+				continue // Current-file symlinks are not log files of their own
+			}
+
+			if !l.isOurLogFile(entry.Name()) {
+				continue
+			}
+
+			if l.shouldRemoveFile(entry, cutoffDate) {
+				l.removeLogFile(dir, entry.Name(), result)
+				continue
+			}
+
+			if info, err := entry.Info(); err == nil {
+				kept = append(kept, dirFile{FileInfo: info, dir: dir})
+			}
 		}
-		return
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	if config.MaxFiles > 0 {
+		kept = l.enforceMaxFiles(config, kept, result)
+	}
+	if config.MaxTotalSize > 0 {
+		l.enforceMaxTotalSize(config, kept, result)
+	}
+
+	return result.removed, errors.Join(result.errs...)
+}
+
+// dirFile is one of our log files together with the directory it was found in
+type dirFile struct {
+	os.FileInfo
+	dir string
+}
+
+// enforceMaxFiles removes the oldest days' files until at most MaxFiles remain.
+// All files of one day (info, error, per-level) are removed together so a day is
+// never left half-deleted, and days with a file currently open are kept.
+func (l *Logger) enforceMaxFiles(config Config, files []dirFile, result *cleanupResult) []dirFile {
+	if len(files) <= config.MaxFiles {
+		return files
+	}
+
+	byDate := make(map[string][]dirFile)
+	var dates []string
+	for _, info := range files {
+		date := logFileDate(info)
+		if _, exists := byDate[date]; !exists {
+			dates = append(dates, date)
 		}
+		byDate[date] = append(byDate[date], info)
+	}
+	sort.Strings(dates)
 
-		if !l.isOurLogFile(entry.Name()) {
+	remaining := len(files)
+	kept := make([]dirFile, 0, len(files))
+	for _, date := range dates {
+		group := byDate[date]
+		if remaining <= config.MaxFiles || l.hasActiveFile(group) {
+			kept = append(kept, group...)
 			continue
 		}
 
-		filePath := filepath.Join(l.config.LogDir, entry.Name())
-		if l.shouldRemoveFile(entry, cutoffDate) {
-			if err := os.Remove(filePath); err != nil {
-				if l.errorLogger != nil {
-					l.Error("Failed to remove old log file", "file", entry.Name(), "error", err)
-				}
+		for _, info := range group {
+			if l.removeLogFile(info.dir, info.Name(), result) {
+				remaining--
 			} else {
-				if l.infoLogger != nil {
-					l.Info("Removed old log file", "file", entry.Name())
-				}
+				kept = append(kept, info)
 			}
 		}
 	}
+	return kept
+}
+
+// logFileDate returns the date a log file belongs to, taken from its name when possible
+func logFileDate(info os.FileInfo) string {
+	name := strings.TrimSuffix(info.Name(), ".log")
+	if len(name) >= len("2006-01-02") {
+		date := name[len(name)-len("2006-01-02"):]
+		if _, err := time.Parse("2006-01-02", date); err == nil {
+			return date
+		}
+	}
+	return info.ModTime().Format("2006-01-02")
+}
+
+// hasActiveFile checks if any of the files is currently open for writing
+func (l *Logger) hasActiveFile(files []dirFile) bool {
+	for _, info := range files {
+		if l.isActiveFile(filepath.Join(info.dir, info.Name())) {
+			return true
+		}
+	}
+	return false
+}
+
+// enforceMaxTotalSize removes the oldest files until the total size fits the cap.
+// Files that are currently open for writing are never removed.
+func (l *Logger) enforceMaxTotalSize(config Config, files []dirFile, result *cleanupResult) {
+	var total int64
+	for _, info := range files {
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, info := range files {
+		if total <= config.MaxTotalSize {
+			return
+		}
+		if l.isActiveFile(filepath.Join(info.dir, info.Name())) {
+			continue
+		}
+		if l.removeLogFile(info.dir, info.Name(), result) {
+			total -= info.Size()
+		}
+	}
+}
+
+// removeLogFile removes one of our log files, logging and recording the outcome
+func (l *Logger) removeLogFile(dir, name string, result *cleanupResult) bool {
+	if result.dryRun {
+		result.removed = append(result.removed, name)
+		return true
+	}
+
+	if err := os.Remove(filepath.Join(dir, name)); err != nil {
+		if os.IsNotExist(err) {
+			return true // Already removed, e.g. by a concurrent cleanup
+		}
+		if l.errorLogger != nil {
+			l.Error("Failed to remove old log file", "file", name, "error", err)
+		}
+		result.errs = append(result.errs, fmt.Errorf("failed to remove %s: %w", name, err))
+		return false
+	}
+
+	result.removed = append(result.removed, name)
+
+	if l.infoLogger != nil {
+		l.Info("Removed old log file", "file", name)
+	}
+	return true
+}
+
+// isActiveFile checks if the path is one of the files currently open for writing
+func (l *Logger) isActiveFile(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, lf := range l.files {
+		if lf.path == absPath {
+			return true
+		}
+	}
+	return l.tenants != nil && slices.Contains(l.tenants.paths(), absPath)
 }
 
 // isOurLogFile checks if the file belongs to this logger instance
 func (l *Logger) isOurLogFile(filename string) bool {
-	if !strings.HasPrefix(filename, l.config.AppName) {
+	appName := l.currentConfig().AppName
+	if !strings.HasPrefix(filename, appName) {
 		return false
 	}
 
@@ -71,8 +378,8 @@ func (l *Logger) isOurLogFile(filename string) bool {
 	}
 
 	expectedPatterns := []string{
-		l.config.AppName + "_",       // app_2024-01-01.log
-		l.config.AppName + "_error_", // app_error_2024-01-01.log
+		appName + "_",       // app_2024-01-01.log
+		appName + "_error_", // app_error_2024-01-01.log
 	}
 
 	for _, pattern := range expectedPatterns {
@@ -96,35 +403,68 @@ func (l *Logger) shouldRemoveFile(entry os.DirEntry, cutoffDate time.Time) bool
 
 // CleanupNow performs immediate cleanup of old log files
 func (l *Logger) CleanupNow() {
+	if l.nop {
+		return
+	}
 	go l.performCleanup()
 }
 
-// GetLogFiles returns list of current log files
+// GetLogFiles returns list of current log files.
+// Files moved to BackupDir are returned joined with BackupDir.
 func (l *Logger) GetLogFiles() ([]string, error) {
-	entries, err := os.ReadDir(l.config.LogDir)
-	if err != nil {
-		return nil, err
+	if l.nop {
+		return nil, nil
 	}
 
+	config := l.currentConfig()
+
 	var logFiles []string
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	for _, dir := range config.logDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if dir == config.BackupDir && os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
 		}
 
-		if l.isOurLogFile(entry.Name()) {
-			logFiles = append(logFiles, entry.Name())
+		for _, entry := range entries {
+			if entry.IsDir() || entry.Type()&fs.ModeSymlink != 0 {
+				continue // Current-file symlinks are not log files of their own
+			}
+
+			if !l.isOurLogFile(entry.Name()) {
+				continue
+			}
+			if dir == config.LogDir {
+				logFiles = append(logFiles, entry.Name())
+			} else {
+				logFiles = append(logFiles, filepath.Join(dir, entry.Name()))
+			}
 		}
 	}
 
 	return logFiles, nil
 }
 
-// GetCurrentLogPaths returns paths to current log files
+// GetCurrentLogPaths returns paths to current log files. With a combined file,
+// both paths point to it.
 func (l *Logger) GetCurrentLogPaths() (infoPath, errorPath string) {
-	today := time.Now().Format("2006-01-02")
-	infoPath = filepath.Join(l.config.LogDir, fmt.Sprintf("%s_%s.log", l.config.AppName, today))
-	errorPath = filepath.Join(l.config.LogDir, fmt.Sprintf("%s_error_%s.log", l.config.AppName, today))
+	if l.nop {
+		return "", ""
+	}
+
+	config := l.currentConfig()
+	today := config.now().Format("2006-01-02")
+	infoKind := ""
+	if config.PerLevelFiles {
+		infoKind = "info"
+	}
+	infoPath = filepath.Join(config.LogDir, config.logFileName(infoKind, today))
+	errorPath = filepath.Join(config.LogDir, config.logFileName("error", today))
+	if config.CombinedFile && !config.PerLevelFiles {
+		errorPath = infoPath // Warnings and errors share the combined file
+	}
 	return
 }
 
@@ -0,0 +1,165 @@
+package iSlogger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// maxStackDepth limits the number of frames captured for a stack trace
+const maxStackDepth = 64
+
+// captureStack returns the current goroutine stack formatted like a panic trace,
+// starting at the frame identified by pc so logger frames are skipped
+func captureStack(pc uintptr) string {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(2, pcs) // skip [runtime.Callers, captureStack]
+	pcs = pcs[:n]
+
+	for i, framePC := range pcs {
+		if framePC == pc {
+			pcs = pcs[i:]
+			break
+		}
+	}
+
+	return formatFrames(pcs)
+}
+
+// formatFrames formats program counters as function and file:line pairs
+func formatFrames(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// stackTraceKey is the attribute holding a captured stack trace
+const stackTraceKey = "stacktrace"
+
+// Recover logs a panic in progress at ERROR with its stack trace, then flushes and
+// lets the goroutine return normally. It must be deferred directly:
+//
+//	defer logger.Recover()
+//
+// Without a panic it does nothing.
+func (l *Logger) Recover() {
+	if r := recover(); r != nil {
+		l.logPanic(r)
+	}
+}
+
+// RecoverAndRepanic logs a panic in progress like Recover, then panics again with
+// the same value. It must be deferred directly.
+func (l *Logger) RecoverAndRepanic() {
+	if r := recover(); r != nil {
+		l.logPanic(r)
+		panic(r)
+	}
+}
+
+// logPanic writes the recovered value with the stack from the panic onwards
+func (l *Logger) logPanic(value any) {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(3, pcs) // skip [runtime.Callers, logPanic, Recover]
+	pcs = pcs[:n]
+
+	// Skip the runtime's panic frames: gopanic, plus frames like panicmem and
+	// sigpanic for runtime errors, so the record points at the panicking code
+	for len(pcs) > 1 {
+		frame, _ := runtime.CallersFrames(pcs[:1]).Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			break
+		}
+		pcs = pcs[1:]
+	}
+
+	var pc uintptr
+	if len(pcs) > 0 {
+		pc = pcs[0]
+	}
+	record := slog.NewRecord(l.now(), slog.LevelError, "panic recovered", pc)
+	record.AddAttrs(
+		slog.Any("panic", value),
+		slog.String(stackTraceKey, formatFrames(pcs)),
+	)
+	l.handle(context.Background(), record)
+	l.Flush()
+}
+
+// addStacksLocked attaches the stack of an error attribute if ErrorStacks is set or,
+// at StackTraceLevel and above, the caller's stack, unless the record already has a
+// stack trace. It must run on the logging goroutine (and with lock held for reading).
+func (l *Logger) addStacksLocked(record slog.Record) slog.Record {
+	if l.config.ErrorStacks && !hasStackTrace(record) {
+		if stack, ok := recordErrorStack(record); ok {
+			record = record.Clone()
+			record.AddAttrs(slog.String(stackTraceKey, stack))
+		}
+	}
+	if l.config.StackTrace && record.Level >= l.config.StackTraceLevel && !hasStackTrace(record) {
+		record = record.Clone()
+		record.AddAttrs(slog.String(stackTraceKey, captureStack(record.PC)))
+	}
+	return record
+}
+
+// hasStackTrace reports whether the record already carries a stack trace
+func hasStackTrace(record slog.Record) bool {
+	found := false
+	record.Attrs(func(a slog.Attr) bool {
+		found = a.Key == stackTraceKey
+		return !found
+	})
+	return found
+}
+
+// recordErrorStack returns the stack carried by the first error attribute that has one
+func recordErrorStack(record slog.Record) (stack string, found bool) {
+	record.Attrs(func(a slog.Attr) bool {
+		if err, ok := a.Value.Resolve().Any().(error); ok {
+			stack, found = errorStack(err)
+		}
+		return !found
+	})
+	return stack, found
+}
+
+// errorStack returns the stack of the innermost error in the chain with a
+// StackTrace method, which is closest to where the error was created
+func errorStack(err error) (string, bool) {
+	var trace reflect.Value
+	for ; err != nil; err = errors.Unwrap(err) {
+		method := reflect.ValueOf(err).MethodByName("StackTrace")
+		if method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() == 1 {
+			trace = method.Call(nil)[0]
+		}
+	}
+	if !trace.IsValid() {
+		return "", false
+	}
+
+	// Program counters, like pkg/errors frames, are formatted like captureStack
+	if trace.Kind() == reflect.Slice && trace.Type().Elem().Kind() == reflect.Uintptr {
+		pcs := make([]uintptr, trace.Len())
+		for i := range pcs {
+			pcs[i] = uintptr(trace.Index(i).Uint())
+		}
+		return formatFrames(pcs), len(pcs) > 0
+	}
+
+	// Anything else is printed in its most detailed form
+	formatted := strings.TrimSpace(fmt.Sprintf("%+v", trace.Interface()))
+	return formatted, formatted != ""
+}
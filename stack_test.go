@@ -0,0 +1,249 @@
+package iSlogger
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestStackTrace(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-stack").
+		WithLogDir("test-logs-stack").
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithStackTrace(slog.LevelError)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-stack")
+
+	logger.Warn("No stack expected")
+	logger.Error("Stack expected")
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %d: %s", len(lines), content)
+	}
+	if strings.Contains(lines[0], "stacktrace=") {
+		t.Errorf("Expected no stacktrace below the configured level, got: %s", lines[0])
+	}
+
+	stackIdx := strings.Index(lines[1], "stacktrace=")
+	if stackIdx < 0 {
+		t.Fatalf("Expected stacktrace attribute on error record, got: %s", lines[1])
+	}
+	stack := lines[1][stackIdx:]
+	if !strings.HasPrefix(stack, `stacktrace="github.com/sarff/iSlogger.TestStackTrace\n\t`) {
+		t.Errorf("Expected stacktrace to start at the caller, got: %s", stack)
+	}
+	if !strings.Contains(stack, "stack_test.go:") {
+		t.Errorf("Expected stacktrace to point at the test file, got: %s", stack)
+	}
+	if strings.Contains(stack, "logger.go") {
+		t.Errorf("Expected logger frames to be skipped, got: %s", stack)
+	}
+}
+
+func TestStackTrace_Disabled(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-stack-disabled").
+		WithLogDir("test-logs-stack-disabled").
+		WithConsoleOutput(false).
+		WithoutBuffering()
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-stack-disabled")
+
+	logger.Error("No stack by default")
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if strings.Contains(string(content), "stacktrace=") {
+		t.Errorf("Expected no stacktrace by default, got: %s", content)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-recover").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithStackTrace(slog.LevelError)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	func() {
+		defer logger.Recover()
+		panicForTest()
+	}()
+
+	// No panic, no record
+	func() {
+		defer logger.Recover()
+	}()
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	output := string(content)
+
+	if got := strings.Count(output, "panic recovered"); got != 1 {
+		t.Fatalf("Expected one recovered panic record, got %d: %s", got, output)
+	}
+	if !strings.Contains(output, `panic="index out of range"`) {
+		t.Errorf("Expected the panic value, got: %s", output)
+	}
+	if got := strings.Count(output, "stacktrace="); got != 1 {
+		t.Errorf("Expected exactly one stacktrace attribute, got %d: %s", got, output)
+	}
+	if !strings.Contains(output, "iSlogger.panicForTest") {
+		t.Errorf("Expected the stack trace to include the panicking function, got: %s", output)
+	}
+}
+
+func TestRecoverAndRepanic(t *testing.T) {
+	logger, err := New(DefaultConfig().WithAppName("test-repanic").WithLogDir(t.TempDir()).WithConsoleOutput(false))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	defer func() {
+		if r := recover(); r != "index out of range" {
+			t.Errorf("Expected the original panic to continue, got %v", r)
+		}
+		_, errorPath := logger.GetCurrentLogPaths()
+		if content, _ := os.ReadFile(errorPath); !strings.Contains(string(content), "panic recovered") {
+			t.Errorf("Expected the panic to be logged before re-panicking, got: %s", content)
+		}
+	}()
+
+	defer logger.RecoverAndRepanic()
+	panicForTest()
+}
+
+func TestRecover_RuntimePanic(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-recover-runtime").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithoutBuffering().
+		WithAddSource(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	func() {
+		defer logger.Recover()
+		nilPanicForTest(nil)
+	}()
+
+	_, errorPath := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	output := string(content)
+
+	if !strings.Contains(output, "stack_test.go:") || strings.Contains(output, "runtime/panic.go") {
+		t.Errorf("Expected the source to be the panicking function, got: %s", output)
+	}
+	if !strings.Contains(output, `stacktrace="github.com/sarff/iSlogger.nilPanicForTest`) {
+		t.Errorf("Expected the stack trace to start at the panicking function, got: %s", output)
+	}
+}
+
+func panicForTest() {
+	panic("index out of range")
+}
+
+func nilPanicForTest(p *int) int {
+	return *p
+}
+
+// tracedError mimics github.com/pkg/errors, recording the stack where it was created
+type tracedError struct {
+	msg   string
+	stack []uintptr
+}
+
+func (e *tracedError) Error() string         { return e.msg }
+func (e *tracedError) StackTrace() []uintptr { return e.stack }
+
+func newTracedError(msg string) error {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(2, pcs) // skip [runtime.Callers, newTracedError]
+	return &tracedError{msg: msg, stack: pcs[:n]}
+}
+
+func openDatabaseForTest() error {
+	return newTracedError("connection refused")
+}
+
+func TestErrorStacks(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-error-stacks").
+		WithLogDir(t.TempDir()).
+		WithConsoleOutput(false).
+		WithInMemoryTail(10).
+		WithStackTrace(slog.LevelError).
+		WithErrorStacks(true)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	traced := fmt.Errorf("startup: %w", openDatabaseForTest())
+	logger.Error("Startup failed", "error", traced)
+	logger.Error("Plain failure", "error", errors.New("no stack"))
+
+	lines := logger.Tail()
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(lines))
+	}
+
+	// The error's own frames replace a fresh capture at the log call
+	if !strings.Contains(lines[0], "iSlogger.openDatabaseForTest") || strings.Count(lines[0], "stacktrace=") != 1 {
+		t.Errorf("Expected the error's stack from its creation site, got: %s", lines[0])
+	}
+	if strings.Contains(lines[0], "iSlogger.newTracedError") {
+		t.Errorf("Expected the stack to start at the caller of newTracedError, got: %s", lines[0])
+	}
+
+	// Errors without a stack fall back to WithStackTrace
+	if strings.Contains(lines[1], "openDatabaseForTest") || !strings.Contains(lines[1], "iSlogger.TestErrorStacks") {
+		t.Errorf("Expected a fresh stack for a plain error, got: %s", lines[1])
+	}
+}
@@ -0,0 +1,30 @@
+package iSlogger
+
+import "sync/atomic"
+
+// LoggerStats reports how many records and attributes were dropped by filtering
+type LoggerStats struct {
+	RateLimited      uint64 // Records dropped by rate limits or adaptive sampling
+	ConditionDropped uint64 // Records dropped by a condition, the package filter or a cancelled context
+	FieldsRemoved    uint64 // Attributes removed by redaction or because they were empty
+	AsyncDropped     uint64 // Records dropped because the async queue was full
+}
+
+// dropStats holds the counters shared by a logger core across rebuilds
+type dropStats struct {
+	rateLimited      atomic.Uint64
+	conditionDropped atomic.Uint64
+	fieldsRemoved    atomic.Uint64
+	asyncDropped     atomic.Uint64
+	levelCounts      [len(levelKinds)]atomic.Uint64 // Records written since the last daily summary, by levelIndex
+}
+
+// Stats returns the drop counters accumulated since the logger was created
+func (l *Logger) Stats() LoggerStats {
+	return LoggerStats{
+		RateLimited:      l.stats.rateLimited.Load(),
+		ConditionDropped: l.stats.conditionDropped.Load(),
+		FieldsRemoved:    l.stats.fieldsRemoved.Load(),
+		AsyncDropped:     l.stats.asyncDropped.Load(),
+	}
+}
@@ -0,0 +1,38 @@
+package iSlogger
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// Printf logs a formatted message at info level, like log.Printf
+func (l *Logger) Printf(format string, args ...any) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+// Println logs its operands at info level, like log.Println
+func (l *Logger) Println(args ...any) {
+	l.log(slog.LevelInfo, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+// StdLogger returns a standard library *log.Logger that writes through this logger at the given level
+func (l *Logger) StdLogger(level slog.Level) *log.Logger {
+	return log.New(&stdLogWriter{logger: l, level: level}, "", 0)
+}
+
+// stdLogWriter adapts log.Logger output to Logger records
+type stdLogWriter struct {
+	logger *Logger
+	level  slog.Level
+}
+
+// Write logs each line written by log.Logger as a separate record
+func (w *stdLogWriter) Write(p []byte) (n int, err error) {
+	var pcs [1]uintptr
+	runtime.Callers(4, pcs[:]) // skip [runtime.Callers, Write, log.Logger.output, log.Logger.Print*]
+	w.logger.logPC(pcs[0], w.level, strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
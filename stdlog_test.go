@@ -0,0 +1,75 @@
+package iSlogger
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPrintf(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-printf").
+		WithLogDir("test-logs-printf").
+		WithConsoleOutput(false).
+		WithoutBuffering()
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-printf")
+
+	logger.Printf("user %s logged in %d times", "john", 3)
+	logger.Println("plain", "line", 42)
+
+	infoPath, _ := logger.GetCurrentLogPaths()
+	content, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+
+	output := string(content)
+	if !strings.Contains(output, `level=INFO msg="user john logged in 3 times"`) {
+		t.Errorf("Expected Printf message in info file, got: %s", output)
+	}
+	if !strings.Contains(output, `level=INFO msg="plain line 42"`) {
+		t.Errorf("Expected Println message in info file, got: %s", output)
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-stdlogger").
+		WithLogDir("test-logs-stdlogger").
+		WithConsoleOutput(false).
+		WithoutBuffering()
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-stdlogger")
+
+	logger.StdLogger(slog.LevelInfo).Printf("from std logger %d", 1)
+	logger.StdLogger(slog.LevelError).Print("std error")
+
+	infoPath, errorPath := logger.GetCurrentLogPaths()
+	infoContent, err := os.ReadFile(infoPath)
+	if err != nil {
+		t.Fatalf("Failed to read info file: %v", err)
+	}
+	if !strings.Contains(string(infoContent), `msg="from std logger 1"`) {
+		t.Errorf("Expected std logger message in info file, got: %s", infoContent)
+	}
+
+	errorContent, err := os.ReadFile(errorPath)
+	if err != nil {
+		t.Fatalf("Failed to read error file: %v", err)
+	}
+	if !strings.Contains(string(errorContent), `level=ERROR msg="std error"`) {
+		t.Errorf("Expected std logger error in error file, got: %s", errorContent)
+	}
+}
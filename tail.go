@@ -0,0 +1,69 @@
+package iSlogger
+
+import (
+	"strings"
+	"sync"
+)
+
+// ringBuffer keeps the last N formatted records in memory
+type ringBuffer struct {
+	mu      sync.Mutex
+	entries []string
+	next    int
+	full    bool
+}
+
+// newRingBuffer creates a new ring buffer with the given capacity
+func newRingBuffer(capacity int) *ringBuffer {
+	return &ringBuffer{
+		entries: make([]string, capacity),
+	}
+}
+
+// Write stores a single formatted record, overwriting the oldest one when full
+func (rb *ringBuffer) Write(p []byte) (n int, err error) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	if len(rb.entries) == 0 {
+		return len(p), nil
+	}
+
+	rb.entries[rb.next] = strings.TrimRight(string(p), "\n")
+	rb.next = (rb.next + 1) % len(rb.entries)
+	if rb.next == 0 {
+		rb.full = true
+	}
+
+	return len(p), nil
+}
+
+// Snapshot returns a copy of the retained records, oldest first
+func (rb *ringBuffer) Snapshot() []string {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	if !rb.full {
+		result := make([]string, rb.next)
+		copy(result, rb.entries[:rb.next])
+		return result
+	}
+
+	result := make([]string, 0, len(rb.entries))
+	result = append(result, rb.entries[rb.next:]...)
+	result = append(result, rb.entries[:rb.next]...)
+	return result
+}
+
+// Tail returns the most recent formatted records kept in memory, oldest first.
+// It returns nil if the in-memory tail is not enabled.
+func (l *Logger) Tail() []string {
+	l.mu.RLock()
+	tail := l.tail
+	l.mu.RUnlock()
+
+	if tail == nil {
+		return nil
+	}
+	return tail.Snapshot()
+}
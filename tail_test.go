@@ -0,0 +1,71 @@
+package iSlogger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInMemoryTail(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-tail").
+		WithLogDir("test-logs-tail").
+		WithLogLevel(slog.LevelDebug).
+		WithConsoleOutput(false).
+		WithInMemoryTail(3)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-tail")
+
+	for i := 1; i <= 5; i++ {
+		logger.Info(fmt.Sprintf("message %d", i))
+	}
+
+	tail := logger.Tail()
+	if len(tail) != 3 {
+		t.Fatalf("Expected 3 records in tail, got %d: %v", len(tail), tail)
+	}
+
+	for i, expected := range []string{"message 3", "message 4", "message 5"} {
+		if !strings.Contains(tail[i], expected) {
+			t.Errorf("Expected tail[%d] to contain %q, got %q", i, expected, tail[i])
+		}
+	}
+}
+
+func TestInMemoryTail_Disabled(t *testing.T) {
+	config := DefaultConfig().
+		WithAppName("test-tail-disabled").
+		WithLogDir("test-logs-tail-disabled").
+		WithConsoleOutput(false)
+
+	logger, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	defer os.RemoveAll("test-logs-tail-disabled")
+
+	logger.Info("Not retained")
+
+	if tail := logger.Tail(); tail != nil {
+		t.Errorf("Expected nil tail when disabled, got %v", tail)
+	}
+}
+
+func TestRingBuffer_PartialFill(t *testing.T) {
+	rb := newRingBuffer(4)
+	rb.Write([]byte("one\n"))
+	rb.Write([]byte("two\n"))
+
+	got := rb.Snapshot()
+	if len(got) != 2 || got[0] != "one" || got[1] != "two" {
+		t.Errorf("Unexpected snapshot: %v", got)
+	}
+}
@@ -0,0 +1,169 @@
+package iSlogger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Placeholders understood by console templates
+const (
+	templateTime   = "{time}"
+	templateLevel  = "{level}"
+	templateMsg    = "{msg}"
+	templateAttrs  = "{attrs}"
+	templateSource = "{source}"
+)
+
+// templatePart is a literal piece of a console template or one of its placeholders
+type templatePart struct {
+	literal     string
+	placeholder string
+}
+
+// parseTemplate splits a template into literals and placeholders. Unknown
+// placeholders are kept as literal text.
+func parseTemplate(template string) []templatePart {
+	var parts []templatePart
+	var literal strings.Builder
+	for template != "" {
+		placeholder := ""
+		for _, p := range []string{templateTime, templateLevel, templateMsg, templateAttrs, templateSource} {
+			if strings.HasPrefix(template, p) {
+				placeholder = p
+				break
+			}
+		}
+		if placeholder == "" {
+			literal.WriteByte(template[0])
+			template = template[1:]
+			continue
+		}
+
+		if literal.Len() > 0 {
+			parts = append(parts, templatePart{literal: literal.String()})
+			literal.Reset()
+		}
+		parts = append(parts, templatePart{placeholder: placeholder})
+		template = template[len(placeholder):]
+	}
+	if literal.Len() > 0 {
+		parts = append(parts, templatePart{literal: literal.String()})
+	}
+	return parts
+}
+
+// templateHandler writes console records laid out by a template such as
+// "{time} [{level}] {msg} {attrs}". Attributes are rendered as logfmt pairs.
+type templateHandler struct {
+	attrs *logfmtHandler // Renders the attributes and holds the writer, options and groups
+	parts []templatePart
+	color bool // Colorize the level
+}
+
+// newTemplateHandler creates a template handler writing to w
+func newTemplateHandler(w io.Writer, template string, opts *slog.HandlerOptions, color bool) *templateHandler {
+	return &templateHandler{
+		attrs: newLogfmtHandler(w, opts),
+		parts: parseTemplate(template),
+		color: color,
+	}
+}
+
+// Enabled reports whether the level reaches the configured minimum
+func (h *templateHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.attrs.Enabled(ctx, level)
+}
+
+// Handle writes the record as a single line following the template
+func (h *templateHandler) Handle(ctx context.Context, record slog.Record) error {
+	var buf []byte
+	for _, part := range h.parts {
+		switch part.placeholder {
+		case "":
+			buf = append(buf, part.literal...)
+		case templateTime:
+			if !record.Time.IsZero() {
+				buf = append(buf, h.builtin(slog.Time(slog.TimeKey, record.Time))...)
+			}
+		case templateLevel:
+			level := h.builtin(slog.Any(slog.LevelKey, record.Level))
+			if h.color {
+				level = levelColor(record.Level) + level + colorReset
+			}
+			buf = append(buf, level...)
+		case templateMsg:
+			buf = append(buf, h.builtin(slog.String(slog.MessageKey, record.Message))...)
+		case templateAttrs:
+			buf = h.appendAttrs(buf, record)
+		case templateSource:
+			if h.attrs.opts.AddSource && record.PC != 0 {
+				frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+				source := &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+				buf = append(buf, h.builtin(slog.Any(slog.SourceKey, source))...)
+			}
+		}
+	}
+
+	// Empty placeholders at the end of the template leave trailing spaces
+	buf = append(bytes.TrimRight(buf, " "), '\n')
+
+	h.attrs.mu.Lock()
+	defer h.attrs.mu.Unlock()
+	_, err := h.attrs.w.Write(buf)
+	return err
+}
+
+// builtin renders a built-in attribute value after ReplaceAttr
+func (h *templateHandler) builtin(attr slog.Attr) string {
+	if h.attrs.opts.ReplaceAttr != nil {
+		attr = h.attrs.opts.ReplaceAttr(nil, attr)
+	}
+	return logfmtValue(attr.Value.Resolve())
+}
+
+// appendAttrs appends the bound and record attributes as space separated logfmt pairs
+func (h *templateHandler) appendAttrs(buf []byte, record slog.Record) []byte {
+	pairs := h.attrs.bound[:len(h.attrs.bound):len(h.attrs.bound)] // Appending copies
+	record.Attrs(func(attr slog.Attr) bool {
+		pairs = h.attrs.appendAttr(pairs, h.attrs.prefix, h.attrs.groups, attr)
+		return true
+	})
+	// Every pair starts with a space; drop the first one
+	if len(pairs) > 0 {
+		pairs = pairs[1:]
+	}
+	return append(buf, pairs...)
+}
+
+// WithAttrs creates a new handler with additional pre-rendered attributes
+func (h *templateHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = h.attrs.WithAttrs(attrs).(*logfmtHandler)
+	return &clone
+}
+
+// WithGroup creates a new handler that nests new keys under the group name
+func (h *templateHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.attrs = h.attrs.WithGroup(name).(*logfmtHandler)
+	return &clone
+}
+
+// consoleHandler creates the handler for a console stream, following the
+// console template if one is set
+func (c Config) consoleHandler(f *os.File, opts *slog.HandlerOptions) slog.Handler {
+	if c.ConsoleTemplate != "" {
+		return newTemplateHandler(f, c.ConsoleTemplate, opts, c.colorEnabled(f))
+	}
+	w := c.consoleWriter(f)
+	handler := c.formatHandler(w, c.consoleFormat(), opts)
+	if cw, ok := w.(*colorWriter); ok {
+		return &colorHandler{handler: handler, writer: cw}
+	}
+	return handler
+}
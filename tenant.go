@@ -0,0 +1,236 @@
+package iSlogger
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// defaultMaxOpenFiles is the number of tenant files kept open when MaxOpenFiles is unset
+const defaultMaxOpenFiles = 32
+
+// tenantFiles holds the open files of the tenants seen recently on the current date.
+// Beyond maxOpen the least recently used file is closed; it is reopened for
+// appending when its tenant logs again.
+type tenantFiles struct {
+	logger  *Logger
+	baseDir string
+	date    string
+	opts    *slog.HandlerOptions
+	maxOpen int
+
+	mu   sync.Mutex
+	open map[string]*list.Element // Values are *tenantFile
+	lru  *list.List               // Most recently used first
+}
+
+// tenantFile is a tenant's open file with the handler writing to it
+type tenantFile struct {
+	tenant  string
+	file    *logFile
+	handler slog.Handler
+	mu      sync.RWMutex // Held for reading by writers and for writing to close the file
+}
+
+// newTenantFiles creates the tenant file set for a date (must be called with lock held)
+func (l *Logger) newTenantFiles(baseDir, date string, opts *slog.HandlerOptions) *tenantFiles {
+	maxOpen := l.config.MaxOpenFiles
+	if maxOpen <= 0 {
+		maxOpen = defaultMaxOpenFiles
+	}
+	return &tenantFiles{
+		logger:  l,
+		baseDir: baseDir,
+		date:    date,
+		opts:    opts,
+		maxOpen: maxOpen,
+		open:    make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// tenantFileKind turns a tenant value into the kind part of its file name. Characters
+// other than letters, digits, '-' and '.' become '_', and names of the regular files'
+// kinds get a leading '_' so a tenant can't write into them.
+func tenantFileKind(tenant string) string {
+	kind := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, tenant)
+	if slices.Contains(levelKinds[:], kind) {
+		kind = "_" + kind
+	}
+	return kind
+}
+
+// acquire returns the tenant's file, opening it if needed and closing the least
+// recently used one beyond maxOpen. The file is locked for writing to it until release.
+func (t *tenantFiles) acquire(tenant string) (*tenantFile, error) {
+	t.mu.Lock()
+	if elem, ok := t.open[tenant]; ok {
+		t.lru.MoveToFront(elem)
+		tf := elem.Value.(*tenantFile)
+		tf.mu.RLock()
+		t.mu.Unlock()
+		return tf, nil
+	}
+
+	config := t.logger.config
+	lf, err := t.logger.newLogFile(t.baseDir, config.logFileName(tenantFileKind(tenant), t.date))
+	if err != nil {
+		t.mu.Unlock()
+		return nil, err
+	}
+	tf := &tenantFile{tenant: tenant, file: lf, handler: config.fileHandler(lf, t.opts)}
+	tf.mu.RLock()
+	t.open[tenant] = t.lru.PushFront(tf)
+
+	var evicted *tenantFile
+	if t.lru.Len() > t.maxOpen {
+		evicted = t.lru.Remove(t.lru.Back()).(*tenantFile)
+		delete(t.open, evicted.tenant)
+	}
+	t.mu.Unlock()
+
+	// Wait for writers still using the evicted file outside the set's lock
+	if evicted != nil {
+		evicted.mu.Lock()
+		evicted.file.Close()
+		evicted.mu.Unlock()
+	}
+	return tf, nil
+}
+
+// release ends a write started with acquire
+func (tf *tenantFile) release() {
+	tf.mu.RUnlock()
+}
+
+// each calls fn for every open tenant file, joining the errors
+func (t *tenantFiles) each(fn func(*logFile) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var errs []error
+	for elem := t.lru.Front(); elem != nil; elem = elem.Next() {
+		if err := fn(elem.Value.(*tenantFile).file); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// paths returns the paths of the open tenant files
+func (t *tenantFiles) paths() []string {
+	var paths []string
+	t.each(func(lf *logFile) error {
+		paths = append(paths, lf.path)
+		return nil
+	})
+	return paths
+}
+
+// close closes every tenant file
+func (t *tenantFiles) close() error {
+	err := t.each((*logFile).Close)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.open)
+	t.lru.Init()
+	return err
+}
+
+// tenantHandler sends records carrying the tenant attribute to their tenant's file
+// and the others to the regular file handler
+type tenantHandler struct {
+	handler slog.Handler // Regular file handler
+	files   *tenantFiles
+	key     string
+	write   bool        // Write tenant records; the error stream leaves them to the info stream, which sees every level
+	tenant  string      // Tenant bound with WithAttrs
+	steps   []chainStep // WithAttrs and WithGroup calls, replayed on the tenant file handlers
+}
+
+// newTenantHandler wraps the regular file handler of a stream
+func newTenantHandler(handler slog.Handler, files *tenantFiles, key string, write bool) *tenantHandler {
+	return &tenantHandler{handler: handler, files: files, key: key, write: write}
+}
+
+// Enabled reports whether either the regular or the tenant files accept the level
+func (h *tenantHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level) || h.write && level >= h.files.opts.Level.Level()
+}
+
+// Handle writes the record to its tenant's file, or to the regular files without a tenant
+func (h *tenantHandler) Handle(ctx context.Context, record slog.Record) error {
+	tenant := h.tenant
+	if !h.grouped() {
+		record.Attrs(func(attr slog.Attr) bool {
+			if attr.Key == h.key {
+				tenant = attr.Value.Resolve().String()
+			}
+			return true
+		})
+	}
+	if tenant == "" {
+		return h.handler.Handle(ctx, record)
+	}
+	if !h.write {
+		return nil
+	}
+
+	tf, err := h.files.acquire(tenant)
+	if err != nil {
+		return fmt.Errorf("failed to open tenant log file: %w", err)
+	}
+	defer tf.release()
+
+	handler := tf.handler
+	for _, step := range h.steps {
+		if step.group != "" {
+			handler = handler.WithGroup(step.group)
+		} else {
+			handler = handler.WithAttrs(slices.Clone(step.attrs))
+		}
+	}
+	return handler.Handle(ctx, record)
+}
+
+// grouped reports whether a group is open, so new attributes can't be the tenant field
+func (h *tenantHandler) grouped() bool {
+	return slices.ContainsFunc(h.steps, func(step chainStep) bool { return step.group != "" })
+}
+
+// WithAttrs creates a new handler with additional attributes, picking up the tenant
+func (h *tenantHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.handler = h.handler.WithAttrs(attrs)
+	clone.steps = append(slices.Clip(h.steps), chainStep{attrs: slices.Clone(attrs)})
+	if !h.grouped() {
+		for _, attr := range attrs {
+			if attr.Key == h.key {
+				clone.tenant = attr.Value.Resolve().String()
+			}
+		}
+	}
+	return &clone
+}
+
+// WithGroup creates a new handler with a group
+func (h *tenantHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.handler = h.handler.WithGroup(name)
+	clone.steps = append(slices.Clip(h.steps), chainStep{group: name})
+	return &clone
+}